@@ -9,6 +9,8 @@ package bookie
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,13 +23,69 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type BookEvent_Type int32
+
+const (
+	BookEvent_TYPE_UNSPECIFIED BookEvent_Type = 0
+	BookEvent_CREATED          BookEvent_Type = 1
+	// Sent for field updates and for undeletes.
+	BookEvent_UPDATED BookEvent_Type = 2
+	// Sent when a book is soft-deleted.
+	BookEvent_DELETED BookEvent_Type = 3
+)
+
+// Enum value maps for BookEvent_Type.
+var (
+	BookEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "CREATED",
+		2: "UPDATED",
+		3: "DELETED",
+	}
+	BookEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"CREATED":          1,
+		"UPDATED":          2,
+		"DELETED":          3,
+	}
+)
+
+func (x BookEvent_Type) Enum() *BookEvent_Type {
+	p := new(BookEvent_Type)
+	*p = x
+	return p
+}
+
+func (x BookEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BookEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_book_proto_enumTypes[0].Descriptor()
+}
+
+func (BookEvent_Type) Type() protoreflect.EnumType {
+	return &file_book_proto_enumTypes[0]
+}
+
+func (x BookEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BookEvent_Type.Descriptor instead.
+func (BookEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{15, 0}
+}
+
 type Book struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
-	Price         int64                  `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Author      string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Price       int64                  `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`
+	// Set when the book has been soft-deleted.
+	DeleteTime    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -97,9 +155,31 @@ func (x *Book) GetPrice() int64 {
 	return 0
 }
 
+func (x *Book) GetDeleteTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeleteTime
+	}
+	return nil
+}
+
 type ListBookRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PerPage       int32                  `protobuf:"varint,1,opt,name=perPage,proto3" json:"perPage,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Deprecated: use page_size.
+	//
+	// Deprecated: Marked as deprecated in book.proto.
+	PerPage int32 `protobuf:"varint,1,opt,name=perPage,proto3" json:"perPage,omitempty"`
+	// Include soft-deleted books in the result.
+	ShowDeleted bool `protobuf:"varint,2,opt,name=show_deleted,json=showDeleted,proto3" json:"show_deleted,omitempty"`
+	// Maximum number of books to return. The server applies a default and caps large values.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token from a previous response, to fetch the following page.
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Filter expression, e.g. `author = "JK Rowling" AND price < 500`.
+	// Supports =, !=, <, <=, >, >=, : (contains), AND, OR, NOT and parentheses.
+	Filter string `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Comma-separated sort fields with optional direction, e.g. "price desc, title".
+	// Defaults to id.
+	OrderBy       string `protobuf:"bytes,6,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -134,6 +214,7 @@ func (*ListBookRequest) Descriptor() ([]byte, []int) {
 	return file_book_proto_rawDescGZIP(), []int{1}
 }
 
+// Deprecated: Marked as deprecated in book.proto.
 func (x *ListBookRequest) GetPerPage() int32 {
 	if x != nil {
 		return x.PerPage
@@ -141,9 +222,53 @@ func (x *ListBookRequest) GetPerPage() int32 {
 	return 0
 }
 
+func (x *ListBookRequest) GetShowDeleted() bool {
+	if x != nil {
+		return x.ShowDeleted
+	}
+	return false
+}
+
+func (x *ListBookRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListBookRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListBookRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *ListBookRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
 type ListBooksResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Books         []*Book                `protobuf:"bytes,1,rep,name=books,proto3" json:"books,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Books []*Book                `protobuf:"bytes,1,rep,name=books,proto3" json:"books,omitempty"`
+	// Token for the next page. Empty when there are no more books.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// Number of books matching the request across all pages.
+	TotalSize int32 `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	// Change-feed revision read before this page was listed. Pass the revision
+	// of the first page as WatchBooks start_after_revision to receive every
+	// change made since the listing; changes already reflected in the pages
+	// may be sent again.
+	Revision      int64 `protobuf:"varint,4,opt,name=revision,proto3" json:"revision,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -185,12 +310,35 @@ func (x *ListBooksResponse) GetBooks() []*Book {
 	return nil
 }
 
+func (x *ListBooksResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListBooksResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+func (x *ListBooksResponse) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
 type CreateBookRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
-	Price         int64                  `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional client-supplied ID. The server generates one when empty.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Author        string `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Price         int64  `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -225,6 +373,13 @@ func (*CreateBookRequest) Descriptor() ([]byte, []int) {
 	return file_book_proto_rawDescGZIP(), []int{3}
 }
 
+func (x *CreateBookRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
 func (x *CreateBookRequest) GetTitle() string {
 	if x != nil {
 		return x.Title
@@ -385,38 +540,1035 @@ func (x *GetByIDResponse) GetBook() *Book {
 	return nil
 }
 
-var File_book_proto protoreflect.FileDescriptor
+type UpdateBookRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The book to update. Its id selects the stored book.
+	Book *Book `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	// Fields of book to overwrite. An empty mask updates every mutable field.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_book_proto_rawDesc = "" +
-	"\n" +
-	"\n" +
-	"book.proto\"|\n" +
-	"\x04Book\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
-	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
-	"\x06author\x18\x04 \x01(\tR\x06author\x12\x14\n" +
-	"\x05price\x18\x05 \x01(\x03R\x05price\"+\n" +
-	"\x0fListBookRequest\x12\x18\n" +
-	"\aperPage\x18\x01 \x01(\x05R\aperPage\"0\n" +
-	"\x11ListBooksResponse\x12\x1b\n" +
-	"\x05books\x18\x01 \x03(\v2\x05.BookR\x05books\"y\n" +
-	"\x11CreateBookRequest\x12\x14\n" +
-	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
-	"\x06author\x18\x04 \x01(\tR\x06author\x12\x14\n" +
-	"\x05price\x18\x05 \x01(\x03R\x05price\"/\n" +
-	"\x12CreateBookResponse\x12\x19\n" +
-	"\x04book\x18\x01 \x01(\v2\x05.BookR\x04book\" \n" +
-	"\x0eGetByIDRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\",\n" +
-	"\x0fGetByIDResponse\x12\x19\n" +
-	"\x04book\x18\x01 \x01(\v2\x05.BookR\x04book2\xa0\x01\n" +
-	"\x06Bookie\x121\n" +
-	"\tListBooks\x12\x10.ListBookRequest\x1a\x12.ListBooksResponse\x125\n" +
-	"\n" +
-	"CreateBook\x12\x12.CreateBookRequest\x1a\x13.CreateBookResponse\x12,\n" +
-	"\aGetByID\x12\x0f.GetByIDRequest\x1a\x10.GetByIDResponseB/Z-github.com/sadhakbj/bookie-grpc/protos/bookieb\x06proto3"
+func (x *UpdateBookRequest) Reset() {
+	*x = UpdateBookRequest{}
+	mi := &file_book_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBookRequest) ProtoMessage() {}
+
+func (x *UpdateBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBookRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBookRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateBookRequest) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+func (x *UpdateBookRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *Book                  `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBookResponse) Reset() {
+	*x = UpdateBookResponse{}
+	mi := &file_book_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBookResponse) ProtoMessage() {}
+
+func (x *UpdateBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBookResponse.ProtoReflect.Descriptor instead.
+func (*UpdateBookResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateBookResponse) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type DeleteBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBookRequest) Reset() {
+	*x = DeleteBookRequest{}
+	mi := &file_book_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBookRequest) ProtoMessage() {}
+
+func (x *DeleteBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBookRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBookRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteBookRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *Book                  `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBookResponse) Reset() {
+	*x = DeleteBookResponse{}
+	mi := &file_book_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBookResponse) ProtoMessage() {}
+
+func (x *DeleteBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBookResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBookResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteBookResponse) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type UndeleteBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteBookRequest) Reset() {
+	*x = UndeleteBookRequest{}
+	mi := &file_book_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteBookRequest) ProtoMessage() {}
+
+func (x *UndeleteBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteBookRequest.ProtoReflect.Descriptor instead.
+func (*UndeleteBookRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UndeleteBookRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UndeleteBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *Book                  `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteBookResponse) Reset() {
+	*x = UndeleteBookResponse{}
+	mi := &file_book_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteBookResponse) ProtoMessage() {}
+
+func (x *UndeleteBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteBookResponse.ProtoReflect.Descriptor instead.
+func (*UndeleteBookResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UndeleteBookResponse) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type StreamBooksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Include soft-deleted books.
+	ShowDeleted bool `protobuf:"varint,1,opt,name=show_deleted,json=showDeleted,proto3" json:"show_deleted,omitempty"`
+	// Filter expression, same syntax as ListBookRequest.filter.
+	Filter string `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Sort order, same syntax as ListBookRequest.order_by.
+	OrderBy string `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// Number of books per response message. The server applies a default and caps large values.
+	ChunkSize     int32 `protobuf:"varint,4,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamBooksRequest) Reset() {
+	*x = StreamBooksRequest{}
+	mi := &file_book_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamBooksRequest) ProtoMessage() {}
+
+func (x *StreamBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamBooksRequest.ProtoReflect.Descriptor instead.
+func (*StreamBooksRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StreamBooksRequest) GetShowDeleted() bool {
+	if x != nil {
+		return x.ShowDeleted
+	}
+	return false
+}
+
+func (x *StreamBooksRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *StreamBooksRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *StreamBooksRequest) GetChunkSize() int32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+type StreamBooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Books         []*Book                `protobuf:"bytes,1,rep,name=books,proto3" json:"books,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamBooksResponse) Reset() {
+	*x = StreamBooksResponse{}
+	mi := &file_book_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamBooksResponse) ProtoMessage() {}
+
+func (x *StreamBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamBooksResponse.ProtoReflect.Descriptor instead.
+func (*StreamBooksResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StreamBooksResponse) GetBooks() []*Book {
+	if x != nil {
+		return x.Books
+	}
+	return nil
+}
+
+// BookEvent describes one change to the catalog.
+type BookEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  BookEvent_Type         `protobuf:"varint,1,opt,name=type,proto3,enum=BookEvent_Type" json:"type,omitempty"`
+	// Monotonically increasing position of the event in the change feed.
+	Revision int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	// The book after the change.
+	Book          *Book                  `protobuf:"bytes,3,opt,name=book,proto3" json:"book,omitempty"`
+	EventTime     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=event_time,json=eventTime,proto3" json:"event_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookEvent) Reset() {
+	*x = BookEvent{}
+	mi := &file_book_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookEvent) ProtoMessage() {}
+
+func (x *BookEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookEvent.ProtoReflect.Descriptor instead.
+func (*BookEvent) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BookEvent) GetType() BookEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return BookEvent_TYPE_UNSPECIFIED
+}
+
+func (x *BookEvent) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *BookEvent) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+func (x *BookEvent) GetEventTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EventTime
+	}
+	return nil
+}
+
+type WatchBooksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Resume after this revision, replaying the events the client missed.
+	// Zero only sends events that happen after the call starts.
+	StartAfterRevision int64 `protobuf:"varint,1,opt,name=start_after_revision,json=startAfterRevision,proto3" json:"start_after_revision,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *WatchBooksRequest) Reset() {
+	*x = WatchBooksRequest{}
+	mi := &file_book_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBooksRequest) ProtoMessage() {}
+
+func (x *WatchBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBooksRequest.ProtoReflect.Descriptor instead.
+func (*WatchBooksRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WatchBooksRequest) GetStartAfterRevision() int64 {
+	if x != nil {
+		return x.StartAfterRevision
+	}
+	return 0
+}
+
+type WatchBooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *BookEvent             `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchBooksResponse) Reset() {
+	*x = WatchBooksResponse{}
+	mi := &file_book_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBooksResponse) ProtoMessage() {}
+
+func (x *WatchBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBooksResponse.ProtoReflect.Descriptor instead.
+func (*WatchBooksResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WatchBooksResponse) GetEvent() *BookEvent {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type BatchCreateBooksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Books to create. Either all of them are created or none.
+	Requests      []*CreateBookRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateBooksRequest) Reset() {
+	*x = BatchCreateBooksRequest{}
+	mi := &file_book_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateBooksRequest) ProtoMessage() {}
+
+func (x *BatchCreateBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateBooksRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateBooksRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BatchCreateBooksRequest) GetRequests() []*CreateBookRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type BatchCreateBooksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Created books, in request order.
+	Books         []*Book `protobuf:"bytes,1,rep,name=books,proto3" json:"books,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateBooksResponse) Reset() {
+	*x = BatchCreateBooksResponse{}
+	mi := &file_book_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateBooksResponse) ProtoMessage() {}
+
+func (x *BatchCreateBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateBooksResponse.ProtoReflect.Descriptor instead.
+func (*BatchCreateBooksResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BatchCreateBooksResponse) GetBooks() []*Book {
+	if x != nil {
+		return x.Books
+	}
+	return nil
+}
+
+type BatchGetBooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetBooksRequest) Reset() {
+	*x = BatchGetBooksRequest{}
+	mi := &file_book_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetBooksRequest) ProtoMessage() {}
+
+func (x *BatchGetBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetBooksRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetBooksRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BatchGetBooksRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type BatchGetBooksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Books that were found, in request order.
+	Books []*Book `protobuf:"bytes,1,rep,name=books,proto3" json:"books,omitempty"`
+	// Requested IDs with no matching book.
+	MissingIds    []string `protobuf:"bytes,2,rep,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetBooksResponse) Reset() {
+	*x = BatchGetBooksResponse{}
+	mi := &file_book_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetBooksResponse) ProtoMessage() {}
+
+func (x *BatchGetBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetBooksResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetBooksResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BatchGetBooksResponse) GetBooks() []*Book {
+	if x != nil {
+		return x.Books
+	}
+	return nil
+}
+
+func (x *BatchGetBooksResponse) GetMissingIds() []string {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+type ImportBooksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Row number in the source file, echoed back in failures.
+	Row           int64              `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Book          *CreateBookRequest `protobuf:"bytes,2,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportBooksRequest) Reset() {
+	*x = ImportBooksRequest{}
+	mi := &file_book_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportBooksRequest) ProtoMessage() {}
+
+func (x *ImportBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportBooksRequest.ProtoReflect.Descriptor instead.
+func (*ImportBooksRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ImportBooksRequest) GetRow() int64 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *ImportBooksRequest) GetBook() *CreateBookRequest {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type ImportBooksResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Created int32                  `protobuf:"varint,1,opt,name=created,proto3" json:"created,omitempty"`
+	// Rows whose id already exists.
+	Skipped int32 `protobuf:"varint,2,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Failed  int32 `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	// Details of failed rows. Capped, so it may be shorter than failed.
+	Failures      []*ImportBooksResponse_Failure `protobuf:"bytes,4,rep,name=failures,proto3" json:"failures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportBooksResponse) Reset() {
+	*x = ImportBooksResponse{}
+	mi := &file_book_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportBooksResponse) ProtoMessage() {}
+
+func (x *ImportBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportBooksResponse.ProtoReflect.Descriptor instead.
+func (*ImportBooksResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ImportBooksResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *ImportBooksResponse) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ImportBooksResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ImportBooksResponse) GetFailures() []*ImportBooksResponse_Failure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+type ImportBooksResponse_Failure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           int64                  `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportBooksResponse_Failure) Reset() {
+	*x = ImportBooksResponse_Failure{}
+	mi := &file_book_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportBooksResponse_Failure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportBooksResponse_Failure) ProtoMessage() {}
+
+func (x *ImportBooksResponse_Failure) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportBooksResponse_Failure.ProtoReflect.Descriptor instead.
+func (*ImportBooksResponse_Failure) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{23, 0}
+}
+
+func (x *ImportBooksResponse_Failure) GetRow() int64 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *ImportBooksResponse_Failure) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_book_proto protoreflect.FileDescriptor
+
+const file_book_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"book.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb9\x01\n" +
+	"\x04Book\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x03R\x05price\x12;\n" +
+	"\vdelete_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"deleteTime\"\xc1\x01\n" +
+	"\x0fListBookRequest\x12\x1c\n" +
+	"\aperPage\x18\x01 \x01(\x05B\x02\x18\x01R\aperPage\x12!\n" +
+	"\fshow_deleted\x18\x02 \x01(\bR\vshowDeleted\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x16\n" +
+	"\x06filter\x18\x05 \x01(\tR\x06filter\x12\x19\n" +
+	"\border_by\x18\x06 \x01(\tR\aorderBy\"\x93\x01\n" +
+	"\x11ListBooksResponse\x12\x1b\n" +
+	"\x05books\x18\x01 \x03(\v2\x05.BookR\x05books\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x05R\ttotalSize\x12\x1a\n" +
+	"\brevision\x18\x04 \x01(\x03R\brevision\"\x89\x01\n" +
+	"\x11CreateBookRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x03R\x05price\"/\n" +
+	"\x12CreateBookResponse\x12\x19\n" +
+	"\x04book\x18\x01 \x01(\v2\x05.BookR\x04book\" \n" +
+	"\x0eGetByIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\",\n" +
+	"\x0fGetByIDResponse\x12\x19\n" +
+	"\x04book\x18\x01 \x01(\v2\x05.BookR\x04book\"k\n" +
+	"\x11UpdateBookRequest\x12\x19\n" +
+	"\x04book\x18\x01 \x01(\v2\x05.BookR\x04book\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"/\n" +
+	"\x12UpdateBookResponse\x12\x19\n" +
+	"\x04book\x18\x01 \x01(\v2\x05.BookR\x04book\"#\n" +
+	"\x11DeleteBookRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
+	"\x12DeleteBookResponse\x12\x19\n" +
+	"\x04book\x18\x01 \x01(\v2\x05.BookR\x04book\"%\n" +
+	"\x13UndeleteBookRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"1\n" +
+	"\x14UndeleteBookResponse\x12\x19\n" +
+	"\x04book\x18\x01 \x01(\v2\x05.BookR\x04book\"\x89\x01\n" +
+	"\x12StreamBooksRequest\x12!\n" +
+	"\fshow_deleted\x18\x01 \x01(\bR\vshowDeleted\x12\x16\n" +
+	"\x06filter\x18\x02 \x01(\tR\x06filter\x12\x19\n" +
+	"\border_by\x18\x03 \x01(\tR\aorderBy\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x04 \x01(\x05R\tchunkSize\"2\n" +
+	"\x13StreamBooksResponse\x12\x1b\n" +
+	"\x05books\x18\x01 \x03(\v2\x05.BookR\x05books\"\xe7\x01\n" +
+	"\tBookEvent\x12#\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x0f.BookEvent.TypeR\x04type\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x03R\brevision\x12\x19\n" +
+	"\x04book\x18\x03 \x01(\v2\x05.BookR\x04book\x129\n" +
+	"\n" +
+	"event_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\teventTime\"C\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\v\n" +
+	"\aCREATED\x10\x01\x12\v\n" +
+	"\aUPDATED\x10\x02\x12\v\n" +
+	"\aDELETED\x10\x03\"E\n" +
+	"\x11WatchBooksRequest\x120\n" +
+	"\x14start_after_revision\x18\x01 \x01(\x03R\x12startAfterRevision\"6\n" +
+	"\x12WatchBooksResponse\x12 \n" +
+	"\x05event\x18\x01 \x01(\v2\n" +
+	".BookEventR\x05event\"I\n" +
+	"\x17BatchCreateBooksRequest\x12.\n" +
+	"\brequests\x18\x01 \x03(\v2\x12.CreateBookRequestR\brequests\"7\n" +
+	"\x18BatchCreateBooksResponse\x12\x1b\n" +
+	"\x05books\x18\x01 \x03(\v2\x05.BookR\x05books\"(\n" +
+	"\x14BatchGetBooksRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"U\n" +
+	"\x15BatchGetBooksResponse\x12\x1b\n" +
+	"\x05books\x18\x01 \x03(\v2\x05.BookR\x05books\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\tR\n" +
+	"missingIds\"N\n" +
+	"\x12ImportBooksRequest\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x03R\x03row\x12&\n" +
+	"\x04book\x18\x02 \x01(\v2\x12.CreateBookRequestR\x04book\"\xd0\x01\n" +
+	"\x13ImportBooksResponse\x12\x18\n" +
+	"\acreated\x18\x01 \x01(\x05R\acreated\x12\x18\n" +
+	"\askipped\x18\x02 \x01(\x05R\askipped\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x05R\x06failed\x128\n" +
+	"\bfailures\x18\x04 \x03(\v2\x1c.ImportBooksResponse.FailureR\bfailures\x1a3\n" +
+	"\aFailure\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x03R\x03row\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason2\x85\x05\n" +
+	"\x06Bookie\x121\n" +
+	"\tListBooks\x12\x10.ListBookRequest\x1a\x12.ListBooksResponse\x125\n" +
+	"\n" +
+	"CreateBook\x12\x12.CreateBookRequest\x1a\x13.CreateBookResponse\x12,\n" +
+	"\aGetByID\x12\x0f.GetByIDRequest\x1a\x10.GetByIDResponse\x125\n" +
+	"\n" +
+	"UpdateBook\x12\x12.UpdateBookRequest\x1a\x13.UpdateBookResponse\x125\n" +
+	"\n" +
+	"DeleteBook\x12\x12.DeleteBookRequest\x1a\x13.DeleteBookResponse\x12;\n" +
+	"\fUndeleteBook\x12\x14.UndeleteBookRequest\x1a\x15.UndeleteBookResponse\x12:\n" +
+	"\vStreamBooks\x12\x13.StreamBooksRequest\x1a\x14.StreamBooksResponse0\x01\x127\n" +
+	"\n" +
+	"WatchBooks\x12\x12.WatchBooksRequest\x1a\x13.WatchBooksResponse0\x01\x12G\n" +
+	"\x10BatchCreateBooks\x12\x18.BatchCreateBooksRequest\x1a\x19.BatchCreateBooksResponse\x12>\n" +
+	"\rBatchGetBooks\x12\x15.BatchGetBooksRequest\x1a\x16.BatchGetBooksResponse\x12:\n" +
+	"\vImportBooks\x12\x13.ImportBooksRequest\x1a\x14.ImportBooksResponse(\x01B/Z-github.com/sadhakbj/bookie-grpc/protos/bookieb\x06proto3"
 
 var (
 	file_book_proto_rawDescOnce sync.Once
@@ -430,31 +1582,85 @@ func file_book_proto_rawDescGZIP() []byte {
 	return file_book_proto_rawDescData
 }
 
-var file_book_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_book_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_book_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_book_proto_goTypes = []any{
-	(*Book)(nil),               // 0: Book
-	(*ListBookRequest)(nil),    // 1: ListBookRequest
-	(*ListBooksResponse)(nil),  // 2: ListBooksResponse
-	(*CreateBookRequest)(nil),  // 3: CreateBookRequest
-	(*CreateBookResponse)(nil), // 4: CreateBookResponse
-	(*GetByIDRequest)(nil),     // 5: GetByIDRequest
-	(*GetByIDResponse)(nil),    // 6: GetByIDResponse
+	(BookEvent_Type)(0),                 // 0: BookEvent.Type
+	(*Book)(nil),                        // 1: Book
+	(*ListBookRequest)(nil),             // 2: ListBookRequest
+	(*ListBooksResponse)(nil),           // 3: ListBooksResponse
+	(*CreateBookRequest)(nil),           // 4: CreateBookRequest
+	(*CreateBookResponse)(nil),          // 5: CreateBookResponse
+	(*GetByIDRequest)(nil),              // 6: GetByIDRequest
+	(*GetByIDResponse)(nil),             // 7: GetByIDResponse
+	(*UpdateBookRequest)(nil),           // 8: UpdateBookRequest
+	(*UpdateBookResponse)(nil),          // 9: UpdateBookResponse
+	(*DeleteBookRequest)(nil),           // 10: DeleteBookRequest
+	(*DeleteBookResponse)(nil),          // 11: DeleteBookResponse
+	(*UndeleteBookRequest)(nil),         // 12: UndeleteBookRequest
+	(*UndeleteBookResponse)(nil),        // 13: UndeleteBookResponse
+	(*StreamBooksRequest)(nil),          // 14: StreamBooksRequest
+	(*StreamBooksResponse)(nil),         // 15: StreamBooksResponse
+	(*BookEvent)(nil),                   // 16: BookEvent
+	(*WatchBooksRequest)(nil),           // 17: WatchBooksRequest
+	(*WatchBooksResponse)(nil),          // 18: WatchBooksResponse
+	(*BatchCreateBooksRequest)(nil),     // 19: BatchCreateBooksRequest
+	(*BatchCreateBooksResponse)(nil),    // 20: BatchCreateBooksResponse
+	(*BatchGetBooksRequest)(nil),        // 21: BatchGetBooksRequest
+	(*BatchGetBooksResponse)(nil),       // 22: BatchGetBooksResponse
+	(*ImportBooksRequest)(nil),          // 23: ImportBooksRequest
+	(*ImportBooksResponse)(nil),         // 24: ImportBooksResponse
+	(*ImportBooksResponse_Failure)(nil), // 25: ImportBooksResponse.Failure
+	(*timestamppb.Timestamp)(nil),       // 26: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),       // 27: google.protobuf.FieldMask
 }
 var file_book_proto_depIdxs = []int32{
-	0, // 0: ListBooksResponse.books:type_name -> Book
-	0, // 1: CreateBookResponse.book:type_name -> Book
-	0, // 2: GetByIDResponse.book:type_name -> Book
-	1, // 3: Bookie.ListBooks:input_type -> ListBookRequest
-	3, // 4: Bookie.CreateBook:input_type -> CreateBookRequest
-	5, // 5: Bookie.GetByID:input_type -> GetByIDRequest
-	2, // 6: Bookie.ListBooks:output_type -> ListBooksResponse
-	4, // 7: Bookie.CreateBook:output_type -> CreateBookResponse
-	6, // 8: Bookie.GetByID:output_type -> GetByIDResponse
-	6, // [6:9] is the sub-list for method output_type
-	3, // [3:6] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	26, // 0: Book.delete_time:type_name -> google.protobuf.Timestamp
+	1,  // 1: ListBooksResponse.books:type_name -> Book
+	1,  // 2: CreateBookResponse.book:type_name -> Book
+	1,  // 3: GetByIDResponse.book:type_name -> Book
+	1,  // 4: UpdateBookRequest.book:type_name -> Book
+	27, // 5: UpdateBookRequest.update_mask:type_name -> google.protobuf.FieldMask
+	1,  // 6: UpdateBookResponse.book:type_name -> Book
+	1,  // 7: DeleteBookResponse.book:type_name -> Book
+	1,  // 8: UndeleteBookResponse.book:type_name -> Book
+	1,  // 9: StreamBooksResponse.books:type_name -> Book
+	0,  // 10: BookEvent.type:type_name -> BookEvent.Type
+	1,  // 11: BookEvent.book:type_name -> Book
+	26, // 12: BookEvent.event_time:type_name -> google.protobuf.Timestamp
+	16, // 13: WatchBooksResponse.event:type_name -> BookEvent
+	4,  // 14: BatchCreateBooksRequest.requests:type_name -> CreateBookRequest
+	1,  // 15: BatchCreateBooksResponse.books:type_name -> Book
+	1,  // 16: BatchGetBooksResponse.books:type_name -> Book
+	4,  // 17: ImportBooksRequest.book:type_name -> CreateBookRequest
+	25, // 18: ImportBooksResponse.failures:type_name -> ImportBooksResponse.Failure
+	2,  // 19: Bookie.ListBooks:input_type -> ListBookRequest
+	4,  // 20: Bookie.CreateBook:input_type -> CreateBookRequest
+	6,  // 21: Bookie.GetByID:input_type -> GetByIDRequest
+	8,  // 22: Bookie.UpdateBook:input_type -> UpdateBookRequest
+	10, // 23: Bookie.DeleteBook:input_type -> DeleteBookRequest
+	12, // 24: Bookie.UndeleteBook:input_type -> UndeleteBookRequest
+	14, // 25: Bookie.StreamBooks:input_type -> StreamBooksRequest
+	17, // 26: Bookie.WatchBooks:input_type -> WatchBooksRequest
+	19, // 27: Bookie.BatchCreateBooks:input_type -> BatchCreateBooksRequest
+	21, // 28: Bookie.BatchGetBooks:input_type -> BatchGetBooksRequest
+	23, // 29: Bookie.ImportBooks:input_type -> ImportBooksRequest
+	3,  // 30: Bookie.ListBooks:output_type -> ListBooksResponse
+	5,  // 31: Bookie.CreateBook:output_type -> CreateBookResponse
+	7,  // 32: Bookie.GetByID:output_type -> GetByIDResponse
+	9,  // 33: Bookie.UpdateBook:output_type -> UpdateBookResponse
+	11, // 34: Bookie.DeleteBook:output_type -> DeleteBookResponse
+	13, // 35: Bookie.UndeleteBook:output_type -> UndeleteBookResponse
+	15, // 36: Bookie.StreamBooks:output_type -> StreamBooksResponse
+	18, // 37: Bookie.WatchBooks:output_type -> WatchBooksResponse
+	20, // 38: Bookie.BatchCreateBooks:output_type -> BatchCreateBooksResponse
+	22, // 39: Bookie.BatchGetBooks:output_type -> BatchGetBooksResponse
+	24, // 40: Bookie.ImportBooks:output_type -> ImportBooksResponse
+	30, // [30:41] is the sub-list for method output_type
+	19, // [19:30] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_book_proto_init() }
@@ -467,13 +1673,14 @@ func file_book_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_book_proto_rawDesc), len(file_book_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      1,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_book_proto_goTypes,
 		DependencyIndexes: file_book_proto_depIdxs,
+		EnumInfos:         file_book_proto_enumTypes,
 		MessageInfos:      file_book_proto_msgTypes,
 	}.Build()
 	File_book_proto = out.File
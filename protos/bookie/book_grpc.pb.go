@@ -19,9 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Bookie_ListBooks_FullMethodName  = "/Bookie/ListBooks"
-	Bookie_CreateBook_FullMethodName = "/Bookie/CreateBook"
-	Bookie_GetByID_FullMethodName    = "/Bookie/GetByID"
+	Bookie_ListBooks_FullMethodName        = "/Bookie/ListBooks"
+	Bookie_CreateBook_FullMethodName       = "/Bookie/CreateBook"
+	Bookie_GetByID_FullMethodName          = "/Bookie/GetByID"
+	Bookie_UpdateBook_FullMethodName       = "/Bookie/UpdateBook"
+	Bookie_DeleteBook_FullMethodName       = "/Bookie/DeleteBook"
+	Bookie_UndeleteBook_FullMethodName     = "/Bookie/UndeleteBook"
+	Bookie_StreamBooks_FullMethodName      = "/Bookie/StreamBooks"
+	Bookie_WatchBooks_FullMethodName       = "/Bookie/WatchBooks"
+	Bookie_BatchCreateBooks_FullMethodName = "/Bookie/BatchCreateBooks"
+	Bookie_BatchGetBooks_FullMethodName    = "/Bookie/BatchGetBooks"
+	Bookie_ImportBooks_FullMethodName      = "/Bookie/ImportBooks"
 )
 
 // BookieClient is the client API for Bookie service.
@@ -31,6 +39,23 @@ type BookieClient interface {
 	ListBooks(ctx context.Context, in *ListBookRequest, opts ...grpc.CallOption) (*ListBooksResponse, error)
 	CreateBook(ctx context.Context, in *CreateBookRequest, opts ...grpc.CallOption) (*CreateBookResponse, error)
 	GetByID(ctx context.Context, in *GetByIDRequest, opts ...grpc.CallOption) (*GetByIDResponse, error)
+	UpdateBook(ctx context.Context, in *UpdateBookRequest, opts ...grpc.CallOption) (*UpdateBookResponse, error)
+	// DeleteBook soft-deletes a book. It is purged after the retention period.
+	DeleteBook(ctx context.Context, in *DeleteBookRequest, opts ...grpc.CallOption) (*DeleteBookResponse, error)
+	// UndeleteBook restores a soft-deleted book.
+	UndeleteBook(ctx context.Context, in *UndeleteBookRequest, opts ...grpc.CallOption) (*UndeleteBookResponse, error)
+	// StreamBooks sends every matching book in chunks, for catalogs too large for one ListBooks response.
+	StreamBooks(ctx context.Context, in *StreamBooksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamBooksResponse], error)
+	// WatchBooks streams catalog changes as they happen. Slow consumers are
+	// disconnected with RESOURCE_EXHAUSTED and can resume from the last revision seen.
+	WatchBooks(ctx context.Context, in *WatchBooksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchBooksResponse], error)
+	// BatchCreateBooks creates several books atomically. Validation failures
+	// are reported per item as INVALID_ARGUMENT with BadRequest details.
+	BatchCreateBooks(ctx context.Context, in *BatchCreateBooksRequest, opts ...grpc.CallOption) (*BatchCreateBooksResponse, error)
+	BatchGetBooks(ctx context.Context, in *BatchGetBooksRequest, opts ...grpc.CallOption) (*BatchGetBooksResponse, error)
+	// ImportBooks creates books row by row from a client stream and returns a
+	// summary. Unlike BatchCreateBooks, valid rows are kept when others fail.
+	ImportBooks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportBooksRequest, ImportBooksResponse], error)
 }
 
 type bookieClient struct {
@@ -71,6 +96,107 @@ func (c *bookieClient) GetByID(ctx context.Context, in *GetByIDRequest, opts ...
 	return out, nil
 }
 
+func (c *bookieClient) UpdateBook(ctx context.Context, in *UpdateBookRequest, opts ...grpc.CallOption) (*UpdateBookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateBookResponse)
+	err := c.cc.Invoke(ctx, Bookie_UpdateBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookieClient) DeleteBook(ctx context.Context, in *DeleteBookRequest, opts ...grpc.CallOption) (*DeleteBookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBookResponse)
+	err := c.cc.Invoke(ctx, Bookie_DeleteBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookieClient) UndeleteBook(ctx context.Context, in *UndeleteBookRequest, opts ...grpc.CallOption) (*UndeleteBookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UndeleteBookResponse)
+	err := c.cc.Invoke(ctx, Bookie_UndeleteBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookieClient) StreamBooks(ctx context.Context, in *StreamBooksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamBooksResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Bookie_ServiceDesc.Streams[0], Bookie_StreamBooks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamBooksRequest, StreamBooksResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bookie_StreamBooksClient = grpc.ServerStreamingClient[StreamBooksResponse]
+
+func (c *bookieClient) WatchBooks(ctx context.Context, in *WatchBooksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchBooksResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Bookie_ServiceDesc.Streams[1], Bookie_WatchBooks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchBooksRequest, WatchBooksResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bookie_WatchBooksClient = grpc.ServerStreamingClient[WatchBooksResponse]
+
+func (c *bookieClient) BatchCreateBooks(ctx context.Context, in *BatchCreateBooksRequest, opts ...grpc.CallOption) (*BatchCreateBooksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCreateBooksResponse)
+	err := c.cc.Invoke(ctx, Bookie_BatchCreateBooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookieClient) BatchGetBooks(ctx context.Context, in *BatchGetBooksRequest, opts ...grpc.CallOption) (*BatchGetBooksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetBooksResponse)
+	err := c.cc.Invoke(ctx, Bookie_BatchGetBooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookieClient) ImportBooks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportBooksRequest, ImportBooksResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Bookie_ServiceDesc.Streams[2], Bookie_ImportBooks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportBooksRequest, ImportBooksResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bookie_ImportBooksClient = grpc.ClientStreamingClient[ImportBooksRequest, ImportBooksResponse]
+
 // BookieServer is the server API for Bookie service.
 // All implementations must embed UnimplementedBookieServer
 // for forward compatibility.
@@ -78,6 +204,23 @@ type BookieServer interface {
 	ListBooks(context.Context, *ListBookRequest) (*ListBooksResponse, error)
 	CreateBook(context.Context, *CreateBookRequest) (*CreateBookResponse, error)
 	GetByID(context.Context, *GetByIDRequest) (*GetByIDResponse, error)
+	UpdateBook(context.Context, *UpdateBookRequest) (*UpdateBookResponse, error)
+	// DeleteBook soft-deletes a book. It is purged after the retention period.
+	DeleteBook(context.Context, *DeleteBookRequest) (*DeleteBookResponse, error)
+	// UndeleteBook restores a soft-deleted book.
+	UndeleteBook(context.Context, *UndeleteBookRequest) (*UndeleteBookResponse, error)
+	// StreamBooks sends every matching book in chunks, for catalogs too large for one ListBooks response.
+	StreamBooks(*StreamBooksRequest, grpc.ServerStreamingServer[StreamBooksResponse]) error
+	// WatchBooks streams catalog changes as they happen. Slow consumers are
+	// disconnected with RESOURCE_EXHAUSTED and can resume from the last revision seen.
+	WatchBooks(*WatchBooksRequest, grpc.ServerStreamingServer[WatchBooksResponse]) error
+	// BatchCreateBooks creates several books atomically. Validation failures
+	// are reported per item as INVALID_ARGUMENT with BadRequest details.
+	BatchCreateBooks(context.Context, *BatchCreateBooksRequest) (*BatchCreateBooksResponse, error)
+	BatchGetBooks(context.Context, *BatchGetBooksRequest) (*BatchGetBooksResponse, error)
+	// ImportBooks creates books row by row from a client stream and returns a
+	// summary. Unlike BatchCreateBooks, valid rows are kept when others fail.
+	ImportBooks(grpc.ClientStreamingServer[ImportBooksRequest, ImportBooksResponse]) error
 	mustEmbedUnimplementedBookieServer()
 }
 
@@ -97,6 +240,30 @@ func (UnimplementedBookieServer) CreateBook(context.Context, *CreateBookRequest)
 func (UnimplementedBookieServer) GetByID(context.Context, *GetByIDRequest) (*GetByIDResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetByID not implemented")
 }
+func (UnimplementedBookieServer) UpdateBook(context.Context, *UpdateBookRequest) (*UpdateBookResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateBook not implemented")
+}
+func (UnimplementedBookieServer) DeleteBook(context.Context, *DeleteBookRequest) (*DeleteBookResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteBook not implemented")
+}
+func (UnimplementedBookieServer) UndeleteBook(context.Context, *UndeleteBookRequest) (*UndeleteBookResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UndeleteBook not implemented")
+}
+func (UnimplementedBookieServer) StreamBooks(*StreamBooksRequest, grpc.ServerStreamingServer[StreamBooksResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamBooks not implemented")
+}
+func (UnimplementedBookieServer) WatchBooks(*WatchBooksRequest, grpc.ServerStreamingServer[WatchBooksResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchBooks not implemented")
+}
+func (UnimplementedBookieServer) BatchCreateBooks(context.Context, *BatchCreateBooksRequest) (*BatchCreateBooksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchCreateBooks not implemented")
+}
+func (UnimplementedBookieServer) BatchGetBooks(context.Context, *BatchGetBooksRequest) (*BatchGetBooksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchGetBooks not implemented")
+}
+func (UnimplementedBookieServer) ImportBooks(grpc.ClientStreamingServer[ImportBooksRequest, ImportBooksResponse]) error {
+	return status.Error(codes.Unimplemented, "method ImportBooks not implemented")
+}
 func (UnimplementedBookieServer) mustEmbedUnimplementedBookieServer() {}
 func (UnimplementedBookieServer) testEmbeddedByValue()                {}
 
@@ -172,6 +339,125 @@ func _Bookie_GetByID_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Bookie_UpdateBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookieServer).UpdateBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bookie_UpdateBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookieServer).UpdateBook(ctx, req.(*UpdateBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bookie_DeleteBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookieServer).DeleteBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bookie_DeleteBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookieServer).DeleteBook(ctx, req.(*DeleteBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bookie_UndeleteBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookieServer).UndeleteBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bookie_UndeleteBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookieServer).UndeleteBook(ctx, req.(*UndeleteBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bookie_StreamBooks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBooksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BookieServer).StreamBooks(m, &grpc.GenericServerStream[StreamBooksRequest, StreamBooksResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bookie_StreamBooksServer = grpc.ServerStreamingServer[StreamBooksResponse]
+
+func _Bookie_WatchBooks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBooksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BookieServer).WatchBooks(m, &grpc.GenericServerStream[WatchBooksRequest, WatchBooksResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bookie_WatchBooksServer = grpc.ServerStreamingServer[WatchBooksResponse]
+
+func _Bookie_BatchCreateBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateBooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookieServer).BatchCreateBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bookie_BatchCreateBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookieServer).BatchCreateBooks(ctx, req.(*BatchCreateBooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bookie_BatchGetBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetBooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookieServer).BatchGetBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bookie_BatchGetBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookieServer).BatchGetBooks(ctx, req.(*BatchGetBooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bookie_ImportBooks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BookieServer).ImportBooks(&grpc.GenericServerStream[ImportBooksRequest, ImportBooksResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bookie_ImportBooksServer = grpc.ClientStreamingServer[ImportBooksRequest, ImportBooksResponse]
+
 // Bookie_ServiceDesc is the grpc.ServiceDesc for Bookie service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,7 +477,43 @@ var Bookie_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetByID",
 			Handler:    _Bookie_GetByID_Handler,
 		},
+		{
+			MethodName: "UpdateBook",
+			Handler:    _Bookie_UpdateBook_Handler,
+		},
+		{
+			MethodName: "DeleteBook",
+			Handler:    _Bookie_DeleteBook_Handler,
+		},
+		{
+			MethodName: "UndeleteBook",
+			Handler:    _Bookie_UndeleteBook_Handler,
+		},
+		{
+			MethodName: "BatchCreateBooks",
+			Handler:    _Bookie_BatchCreateBooks_Handler,
+		},
+		{
+			MethodName: "BatchGetBooks",
+			Handler:    _Bookie_BatchGetBooks_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamBooks",
+			Handler:       _Bookie_StreamBooks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchBooks",
+			Handler:       _Bookie_WatchBooks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportBooks",
+			Handler:       _Bookie_ImportBooks_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "book.proto",
 }
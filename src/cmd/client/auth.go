@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/sadhakbj/bookie-grpc/src/internal/auth"
+	"github.com/sadhakbj/bookie-grpc/src/internal/config"
+)
+
+// realm names the book API in WWW-Authenticate challenges.
+const realm = "bookie"
+
+// newAuthenticator builds the authenticator of the book routes from cfg,
+// accepting API keys, JWT bearer tokens or both, as configured.
+func newAuthenticator(cfg config.Auth) (*auth.Authenticator, error) {
+	var keys *auth.APIKeys
+	if len(cfg.APIKeys) > 0 {
+		var err error
+		if keys, err = auth.NewAPIKeys(cfg.APIKeys); err != nil {
+			return nil, err
+		}
+	}
+	var token *auth.TokenVerifier
+	if cfg.JWKSFile != "" {
+		var err error
+		if token, err = auth.NewTokenVerifier(cfg.JWKSFile, cfg.JWTIssuer, cfg.JWTAudience); err != nil {
+			return nil, err
+		}
+	}
+	return auth.NewAuthenticator(keys, token, realm), nil
+}
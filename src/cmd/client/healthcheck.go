@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/config"
+	"github.com/sadhakbj/bookie-grpc/src/internal/healthcheck"
+)
+
+// runHealthcheck implements "client healthcheck": it exits 0 when /healthz
+// of the client on the configured port answers 200 and 1 otherwise.
+func runHealthcheck() int {
+	cfg, err := config.LoadClient()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid configuration:", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthcheck.Timeout)
+	defer cancel()
+	if err := healthcheck.HTTP(ctx, "http://localhost:"+cfg.Port+"/healthz"); err != nil {
+		fmt.Fprintln(os.Stderr, "Unhealthy:", err)
+		return 1
+	}
+	return 0
+}
@@ -3,24 +3,61 @@ package main
 
 import (
 	"context"
+	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/admin"
 	"github.com/sadhakbj/bookie-grpc/src/internal/client/controllers"
+	"github.com/sadhakbj/bookie-grpc/src/internal/client/middleware"
+	"github.com/sadhakbj/bookie-grpc/src/internal/config"
 	"github.com/sadhakbj/bookie-grpc/src/internal/services/books"
+	"github.com/sadhakbj/bookie-grpc/src/internal/telemetry"
 	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
 )
 
 var bookClient *books.GRPCClient
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		os.Exit(runHealthcheck())
+	}
+
 	logger := utils.InitializeLogger("bookie-client", true)
+	slog.SetDefault(logger)
+
+	cfg, err := config.LoadClient()
+	if err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+
+	shutdownTracing, err := telemetry.Setup(context.Background(), "bookie-client", cfg.Tracing)
+	if err != nil {
+		log.Fatal("Could not initialize tracing: ", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("Error while flushing traces", "error", err)
+		}
+	}()
+
+	creds, certReloader, err := clientCredentials(cfg.TLS)
+	if err != nil {
+		log.Fatal("Could not load TLS certificates: ", err)
+	}
+	if certReloader != nil {
+		reloadCtx, stopReload := context.WithCancel(context.Background())
+		defer stopReload()
+		go certReloader.Run(reloadCtx, logger, cfg.TLS.ReloadInterval)
+	}
 
-	var err error
-	bookClient, err = books.NewGRPCClient()
+	bookClient, err = books.NewGRPCClient(cfg.GRPCServerAddr, creds)
 	if err != nil {
 		logger.Error("Failed to initialize BookClient: %v", "error", err)
 	}
@@ -37,20 +74,49 @@ func main() {
 		logger.Error("Failed to initialize BookController: %v", "error", err)
 	}
 
-	mux.HandleFunc("GET /books/{id}", booksController.FetchBookByID)
-	mux.HandleFunc("GET /books", booksController.FetchAllBooks)
+	var routeMiddleware []func(http.Handler) http.Handler
+	if cfg.Auth.Enabled() {
+		authn, err := newAuthenticator(cfg.Auth)
+		if err != nil {
+			log.Fatal("Invalid authentication configuration: ", err)
+		}
+		routeMiddleware = append(routeMiddleware, middleware.Authenticate(authn))
+	} else {
+		logger.Warn("Authentication disabled: set API_KEYS or JWT_JWKS_FILE to protect the book routes")
+	}
 
-	// Get HTTP server port from environment variable, default to 8080
-	httpPort := os.Getenv("HTTP_PORT")
-	if httpPort == "" {
-		httpPort = "8080"
+	if err := booksController.RegisterRoutes(mux, cfg.RequestTimeout, cfg.RouteTimeouts, routeMiddleware...); err != nil {
+		log.Fatal("Invalid ROUTE_TIMEOUTS: ", err)
 	}
+	controllers.NewHealthController(bookClient).RegisterRoutes(mux)
+	httpPort := cfg.Port
+
+	// Every request gets a span, continuing the trace of an incoming
+	// traceparent header. Routes rename it after their pattern.
+	handler := otelhttp.NewHandler(middleware.RequestID(middleware.AccessLog(middleware.Metrics(mux))), "bookie-client",
+		otelhttp.WithSpanNameFormatter(controllers.SpanName),
+	)
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:    ":" + httpPort,
-		Handler: mux,
+		Handler: handler,
 	}
+	// Shutdown waits for in-flight requests but never for event streams,
+	// which only end when told to
+	server.RegisterOnShutdown(booksController.Shutdown)
+
+	// Metrics are served on their own port, away from the public API
+	adminServer := admin.NewServer(cfg.AdminPort, admin.Options{Debug: cfg.Debug, Config: cfg})
+	if cfg.Debug {
+		logger.Warn("Debug endpoints enabled: pprof, channelz and config dump")
+	}
+	go func() {
+		logger.Info("Admin server started on port: " + cfg.AdminPort)
+		if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Failed to start admin server", "error", err)
+		}
+	}()
 
 	// Create a channel to receive OS signals
 	signalChan := make(chan os.Signal, 1)
@@ -79,4 +145,7 @@ func main() {
 	} else {
 		logger.Info("Server stopped gracefully")
 	}
+	if err := adminServer.Shutdown(ctx); err != nil {
+		logger.Error("Admin server shutdown error", "error", err)
+	}
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/config"
+	"github.com/sadhakbj/bookie-grpc/src/internal/tlsconfig"
+)
+
+// clientCredentials returns the transport credentials used to dial the gRPC
+// server and, when TLS is on, the reloader that keeps its files current.
+func clientCredentials(cfg config.ClientTLS) (credentials.TransportCredentials, *tlsconfig.Reloader, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil, nil
+	}
+	reloader, err := tlsconfig.NewReloader(tlsconfig.Files{
+		CertFile: cfg.CertFile,
+		KeyFile:  cfg.KeyFile,
+		CAFile:   cfg.CAFile,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentials.NewTLS(tlsconfig.Client(reloader, cfg.ServerName)), reloader, nil
+}
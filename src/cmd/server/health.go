@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/repository"
+)
+
+// pingTimeout bounds one storage readiness check.
+const pingTimeout = 2 * time.Second
+
+// runHealthChecks pings the storage every interval until ctx is cancelled and
+// reports the result for the whole server ("") and for the Bookie service.
+// The first check runs immediately.
+func runHealthChecks(ctx context.Context, logger *slog.Logger, healthServer *health.Server, repo repository.BookRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if err := pingStorage(ctx, repo); err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			if status != last {
+				logger.Error("Storage is not ready", "error", err)
+			}
+		} else if last == healthpb.HealthCheckResponse_NOT_SERVING {
+			logger.Info("Storage is ready again")
+		}
+		last = status
+		healthServer.SetServingStatus("", status)
+		healthServer.SetServingStatus(bookiePb.Bookie_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pingStorage checks the storage, giving up after pingTimeout.
+func pingStorage(ctx context.Context, repo repository.BookRepository) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	return repo.Ping(ctx)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/repository"
+)
+
+// flakyRepository fails Ping while down is set.
+type flakyRepository struct {
+	repository.BookRepository
+	down atomic.Bool
+}
+
+func (r *flakyRepository) Ping(context.Context) error {
+	if r.down.Load() {
+		return errors.New("database is locked")
+	}
+	return nil
+}
+
+func TestRunHealthChecks(t *testing.T) {
+	repo := &flakyRepository{BookRepository: repository.NewMemoryRepository(nil)}
+	healthServer := health.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runHealthChecks(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), healthServer, repo, 5*time.Millisecond)
+
+	// waitFor polls until both the server and the Bookie service report want.
+	waitFor := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			all, err1 := healthServer.Check(ctx, &healthpb.HealthCheckRequest{})
+			bookie, err2 := healthServer.Check(ctx, &healthpb.HealthCheckRequest{Service: "Bookie"})
+			if err1 == nil && err2 == nil && all.GetStatus() == want && bookie.GetStatus() == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("health = %v, %v (errors %v, %v), want %v", all.GetStatus(), bookie.GetStatus(), err1, err2, want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitFor(healthpb.HealthCheckResponse_SERVING)
+	repo.down.Store(true)
+	waitFor(healthpb.HealthCheckResponse_NOT_SERVING)
+	repo.down.Store(false)
+	waitFor(healthpb.HealthCheckResponse_SERVING)
+
+	// Shutdown wins over later successful checks.
+	healthServer.Shutdown()
+	time.Sleep(20 * time.Millisecond)
+	waitFor(healthpb.HealthCheckResponse_NOT_SERVING)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/config"
+	"github.com/sadhakbj/bookie-grpc/src/internal/healthcheck"
+)
+
+// runHealthcheck implements "server healthcheck": it exits 0 when the server
+// on the configured port reports SERVING and 1 otherwise.
+func runHealthcheck() int {
+	cfg, err := config.LoadServer()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid configuration:", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthcheck.Timeout)
+	defer cancel()
+	creds, err := healthcheckCredentials(cfg.TLS)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid TLS configuration:", err)
+		return 1
+	}
+	if err := healthcheck.GRPC(ctx, "localhost:"+cfg.Port, "", creds); err != nil {
+		fmt.Fprintln(os.Stderr, "Unhealthy:", err)
+		return 1
+	}
+	return 0
+}
+
+// healthcheckCredentials returns the credentials to probe the local server
+// with. Over TLS the probe does not verify the server, which is this very
+// process, and presents the server certificate in case mutual TLS is on; the
+// certificate must then allow client authentication too.
+func healthcheckCredentials(cfg config.ServerTLS) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled() {
+		return insecure.NewCredentials(), nil
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		InsecureSkipVerify: true, //nolint:gosec // loopback probe of this process
+	}), nil
+}
@@ -5,90 +5,140 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/admin"
+	"github.com/sadhakbj/bookie-grpc/src/internal/auth"
+	"github.com/sadhakbj/bookie-grpc/src/internal/config"
+	"github.com/sadhakbj/bookie-grpc/src/internal/events"
+	"github.com/sadhakbj/bookie-grpc/src/internal/metrics"
+	"github.com/sadhakbj/bookie-grpc/src/internal/repository"
+	"github.com/sadhakbj/bookie-grpc/src/internal/requestid"
+	"github.com/sadhakbj/bookie-grpc/src/internal/telemetry"
 	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+	"github.com/sadhakbj/bookie-grpc/src/internal/validation"
 )
 
-var books = []*bookiePb.Book{
-	{
-		Id:          "1234",
-		Title:       "Harry Potter",
-		Price:       120,
-		Author:      "JK Rowling",
-		Description: "a lovely book",
-	},
-	{
-		Id:          "4567",
-		Title:       "Game of life",
-		Price:       450,
-		Author:      "Author Two",
-		Description: "This is a test",
-	},
-}
+const (
+	// watchHistorySize is how many change events WatchBooks clients can resume from.
+	watchHistorySize = 1000
+	// watchBufferSize is how many events a WatchBooks client may lag behind before it is dropped.
+	watchBufferSize = 64
+)
 
-type bookieService struct {
-	bookiePb.UnimplementedBookieServer
-}
+// newRepository picks the storage backend from the configuration. The returned
+// function releases the resources held by the repository.
+func newRepository(ctx context.Context, cfg config.Server) (repository.BookRepository, func() error, error) {
+	if cfg.DBPath == "" {
+		repo := repository.NewMemoryRepository(repository.SeedBooks())
+		return repository.NewTracedRepository(repo, "memory"), func() error { return nil }, nil
+	}
 
-func (s *bookieService) ListBooks(_ context.Context, req *bookiePb.ListBookRequest) (*bookiePb.ListBooksResponse, error) {
-	fmt.Println("this is just a test")
-	fmt.Println(req)
-	return &bookiePb.ListBooksResponse{Books: books}, nil
+	repo, err := repository.NewSQLiteRepository(ctx, cfg.DBPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return repository.NewTracedRepository(repo, "sqlite"), repo.Close, nil
 }
 
-func (s *bookieService) CreateBook(_ context.Context, input *bookiePb.CreateBookRequest) (*bookiePb.CreateBookResponse, error) {
-	newBook := &bookiePb.Book{
-		Id:          "8910",
-		Title:       input.Title,
-		Price:       input.Price,
-		Author:      input.Author,
-		Description: input.Description,
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		os.Exit(runHealthcheck())
 	}
-	books = append(books, newBook)
 
-	return &bookiePb.CreateBookResponse{
-		Book: newBook,
-	}, nil
-}
-
-func (s *bookieService) GetByID(_ context.Context, input *bookiePb.GetByIDRequest) (*bookiePb.GetByIDResponse, error) {
-	fmt.Println("input is", input)
-	if input.GetId() == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "Please provide id")
+	cfg, err := config.LoadServer()
+	if err != nil {
+		log.Fatal("Invalid configuration: ", err)
 	}
-	for _, book := range books {
-		if book.Id == input.Id {
-			return &bookiePb.GetByIDResponse{Book: book}, nil
-		}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.Port))
+	if err != nil {
+		log.Fatal("Could not listen: ", err)
 	}
 
-	return nil, status.Errorf(codes.NotFound, "Book with ID %s not found", input.Id)
-}
+	logger := utils.InitializeLogger("bookie-grpc", false)
+	slog.SetDefault(logger)
 
-func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8020"
+	shutdownTracing, err := telemetry.Setup(context.Background(), "bookie-grpc", cfg.Tracing)
+	if err != nil {
+		log.Fatal("Could not initialize tracing: ", err)
 	}
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("Error while flushing traces", "error", err)
+		}
+	}()
+
+	repo, closeRepo, err := newRepository(context.Background(), cfg)
 	if err != nil {
-		log.Fatal("Could not listen: ", err)
+		log.Fatal("Could not initialize storage: ", err)
+	}
+	defer func() {
+		if err := closeRepo(); err != nil {
+			logger.Error("Error while closing storage", "error", err)
+		}
+	}()
+	if cfg.DBPath != "" {
+		logger.Info("Using SQLite storage", "path", cfg.DBPath)
 	}
 
-	logger := utils.InitializeLogger("bookie-grpc", false)
+	creds, certReloader, err := serverCredentials(cfg.TLS)
+	if err != nil {
+		log.Fatal("Could not load TLS certificates: ", err)
+	}
+	if cfg.TLS.Enabled() {
+		logger.Info("Serving gRPC over TLS", "cert", cfg.TLS.CertFile, "mutual", cfg.TLS.ClientCAFile != "")
+	}
+	if cfg.TLS.ClientCAFile == "" {
+		logger.Info("Mutual TLS is off, principals forwarded by callers are ignored")
+	}
 
 	logger.Info("Creating a new server")
-	grpcServer := grpc.NewServer()
-	bookiePb.RegisterBookieServer(grpcServer, &bookieService{})
+	grpcServer := grpc.NewServer(append(serverOptions(), grpc.Creds(creds))...)
+	broker := events.NewBroker(watchHistorySize, watchBufferSize)
+	bookiePb.RegisterBookieServer(grpcServer, newBookieService(repo, broker))
+
+	// The health service reports NOT_SERVING until storage answers a ping
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus(bookiePb.Bookie_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	if cfg.Debug {
+		// Lets grpcurl and similar tools discover the API without book.proto
+		reflection.Register(grpcServer)
+		logger.Warn("Debug endpoints enabled: gRPC reflection, pprof, channelz and config dump")
+	}
+
+	prometheus.MustRegister(metrics.NewBooksCollector(repo))
+	adminServer := admin.NewServer(cfg.AdminPort, admin.Options{Debug: cfg.Debug, Config: cfg})
+	go func() {
+		logger.Info("Admin server started on port: " + cfg.AdminPort)
+		if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Failed to start admin server", "error", err)
+		}
+	}()
+
+	// Purge soft-deleted books, check storage and reload certificates in the
+	// background until shutdown
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go runPurger(backgroundCtx, logger, repo, cfg.DeletedRetention, cfg.PurgeInterval)
+	go runHealthChecks(backgroundCtx, logger, healthServer, repo, cfg.HealthCheckInterval)
+	if certReloader != nil {
+		go certReloader.Run(backgroundCtx, logger, cfg.TLS.ReloadInterval)
+	}
 
 	// Create a channel to receive OS signals
 	signalChan := make(chan os.Signal, 1)
@@ -96,7 +146,7 @@ func main() {
 
 	// Start the server in a goroutine
 	go func() {
-		logger.Info("Successfully started the server on port: " + port)
+		logger.Info("Successfully started the server on port: " + cfg.Port)
 		if e := grpcServer.Serve(listener); e != nil {
 			logger.Error("Failed to serve: %v", "error", e)
 		}
@@ -108,6 +158,33 @@ func main() {
 
 	// Gracefully stop the server
 	logger.Info("Gracefully stopping the gRPC server...")
+	// Report NOT_SERVING first so health checks stop routing traffic here
+	healthServer.Shutdown()
+	stopBackground()
+	// End open WatchBooks streams, GracefulStop waits for every RPC to return
+	broker.Close()
 	grpcServer.GracefulStop()
+	if err := adminServer.Close(); err != nil {
+		logger.Error("Error while closing admin server", "error", err)
+	}
 	logger.Info("Server stopped gracefully")
 }
+
+// serverOptions are the options shared by the server and its tests.
+func serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			metrics.UnaryServerInterceptor(),
+			requestid.UnaryServerInterceptor(),
+			auth.UnaryServerInterceptor(),
+			validation.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			metrics.StreamServerInterceptor(),
+			requestid.StreamServerInterceptor(),
+			auth.StreamServerInterceptor(),
+			validation.StreamServerInterceptor(),
+		),
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/repository"
+)
+
+// runPurger permanently removes books that were soft-deleted longer than
+// retention ago. It checks every interval until ctx is cancelled.
+func runPurger(ctx context.Context, logger *slog.Logger, repo repository.BookRepository, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := repo.Purge(ctx, time.Now().Add(-retention))
+			if err != nil {
+				logger.Error("Failed to purge deleted books", "error", err)
+				continue
+			}
+			if purged > 0 {
+				logger.Info("Purged deleted books", "count", purged)
+			}
+		}
+	}
+}
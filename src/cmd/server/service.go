@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/events"
+	"github.com/sadhakbj/bookie-grpc/src/internal/query"
+	"github.com/sadhakbj/bookie-grpc/src/internal/repository"
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+	"github.com/sadhakbj/bookie-grpc/src/internal/validation"
+)
+
+const (
+	// defaultPageSize is used when ListBooks is called without a page size.
+	defaultPageSize = 25
+	// maxPageSize caps the page size a client can ask for.
+	maxPageSize = 100
+	// defaultChunkSize is the number of books per StreamBooks message.
+	defaultChunkSize = 100
+	// maxChunkSize caps StreamBooks messages well below the 4MB gRPC limit.
+	maxChunkSize = 500
+	// maxImportFailures caps the failure details returned by ImportBooks.
+	maxImportFailures = 1000
+)
+
+type bookieService struct {
+	bookiePb.UnimplementedBookieServer
+	repo   repository.BookRepository
+	broker *events.Broker
+	// writeMu is held from a repository write until its event is published,
+	// so event revisions follow the order in which writes were applied.
+	writeMu sync.Mutex
+}
+
+// newBookieService creates the Bookie gRPC service backed by the given
+// repository. Changes are published to broker for WatchBooks.
+func newBookieService(repo repository.BookRepository, broker *events.Broker) *bookieService {
+	return &bookieService{repo: repo, broker: broker}
+}
+
+func (s *bookieService) ListBooks(ctx context.Context, req *bookiePb.ListBookRequest) (*bookiePb.ListBooksResponse, error) {
+	utils.LoggerFromContext(ctx).Debug("Listing books", "filter", req.GetFilter(), "order_by", req.GetOrderBy(), "page_size", req.GetPageSize())
+	pageSize := resolvePageSize(req)
+	filter, order, err := parseListing(req.GetFilter(), req.GetOrderBy())
+	if err != nil {
+		return nil, err
+	}
+	keep := listed(req.GetShowDeleted(), filter)
+
+	// The revision is read before the books, so watching from it may repeat
+	// a change the page already shows but never misses one.
+	revision := s.broker.Revision()
+	queryID := fmt.Sprintf("show_deleted=%t;filter=%s", req.GetShowDeleted(), req.GetFilter())
+	after, err := query.DecodePageToken(req.GetPageToken(), order, queryID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid page_token")
+	}
+
+	// One book past the page tells whether another page follows.
+	books := make([]*bookiePb.Book, 0, pageSize+1)
+	for book, err := range s.scanBooks(ctx, order, after, pageSize+1, keep) {
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+		if len(books) > pageSize {
+			break
+		}
+	}
+	res := &bookiePb.ListBooksResponse{Books: books, Revision: revision}
+	if len(books) > pageSize {
+		res.Books = books[:pageSize]
+		res.NextPageToken = query.EncodePageToken(books[pageSize-1], order, queryID)
+	}
+
+	total, err := s.countBooks(ctx, req.GetShowDeleted(), filter)
+	if err != nil {
+		return nil, err
+	}
+	res.TotalSize = int32(total)
+	return res, nil
+}
+
+func (s *bookieService) StreamBooks(req *bookiePb.StreamBooksRequest, stream grpc.ServerStreamingServer[bookiePb.StreamBooksResponse]) error {
+	chunkSize := resolveChunkSize(req.GetChunkSize())
+	filter, order, err := parseListing(req.GetFilter(), req.GetOrderBy())
+	if err != nil {
+		return err
+	}
+	keep := listed(req.GetShowDeleted(), filter)
+
+	// Send blocks while the client's HTTP/2 flow-control window is full, so a
+	// slow reader throttles the reads instead of them buffering.
+	chunk := make([]*bookiePb.Book, 0, chunkSize)
+	for book, err := range s.scanBooks(stream.Context(), order, nil, chunkSize, keep) {
+		if err != nil {
+			return err
+		}
+		chunk = append(chunk, book)
+		if len(chunk) == chunkSize {
+			if err := stream.Send(&bookiePb.StreamBooksResponse{Books: chunk}); err != nil {
+				return err
+			}
+			chunk = make([]*bookiePb.Book, 0, chunkSize)
+		}
+	}
+
+	if len(chunk) > 0 {
+		return stream.Send(&bookiePb.StreamBooksResponse{Books: chunk})
+	}
+	return nil
+}
+
+func (s *bookieService) CreateBook(ctx context.Context, input *bookiePb.CreateBookRequest) (*bookiePb.CreateBookResponse, error) {
+	id := input.GetId()
+	if id == "" {
+		generated, err := newBookID()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Could not generate book id: %v", err)
+		}
+		id = generated
+	}
+
+	newBook, err := s.commit(bookiePb.BookEvent_CREATED, func() (*bookiePb.Book, error) {
+		return s.repo.Create(ctx, &bookiePb.Book{
+			Id:          id,
+			Title:       input.Title,
+			Price:       input.Price,
+			Author:      input.Author,
+			Description: input.Description,
+		})
+	})
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return nil, status.Errorf(codes.AlreadyExists, "Book with ID %s already exists", id)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not create book: %v", err)
+	}
+
+	return &bookiePb.CreateBookResponse{
+		Book: newBook,
+	}, nil
+}
+
+func (s *bookieService) GetByID(ctx context.Context, input *bookiePb.GetByIDRequest) (*bookiePb.GetByIDResponse, error) {
+	utils.LoggerFromContext(ctx).Debug("Getting book", "id", input.GetId())
+	book, err := s.getLiveBook(ctx, input.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bookiePb.GetByIDResponse{Book: book}, nil
+}
+
+// newBookID returns a UUIDv7, which embeds a millisecond timestamp so
+// generated IDs sort in creation order.
+func newBookID() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (s *bookieService) UpdateBook(ctx context.Context, input *bookiePb.UpdateBookRequest) (*bookiePb.UpdateBookResponse, error) {
+	patch := input.GetBook()
+	paths := input.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = validation.UpdatableBookFields
+	}
+
+	updated, err := s.commit(bookiePb.BookEvent_UPDATED, func() (*bookiePb.Book, error) {
+		return s.repo.Update(ctx, patch, paths)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "Book with ID %s not found", patch.GetId())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not update book: %v", err)
+	}
+
+	return &bookiePb.UpdateBookResponse{Book: updated}, nil
+}
+
+func (s *bookieService) DeleteBook(ctx context.Context, input *bookiePb.DeleteBookRequest) (*bookiePb.DeleteBookResponse, error) {
+	deleted, err := s.commit(bookiePb.BookEvent_DELETED, func() (*bookiePb.Book, error) {
+		return s.repo.SoftDelete(ctx, input.GetId(), time.Now())
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "Book with ID %s not found", input.GetId())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not delete book: %v", err)
+	}
+
+	return &bookiePb.DeleteBookResponse{Book: deleted}, nil
+}
+
+func (s *bookieService) UndeleteBook(ctx context.Context, input *bookiePb.UndeleteBookRequest) (*bookiePb.UndeleteBookResponse, error) {
+	restored, err := s.commit(bookiePb.BookEvent_UPDATED, func() (*bookiePb.Book, error) {
+		return s.repo.Undelete(ctx, input.GetId())
+	})
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "Book with ID %s not found", input.GetId())
+	case errors.Is(err, repository.ErrNotDeleted):
+		return nil, status.Errorf(codes.FailedPrecondition, "Book with ID %s is not deleted", input.GetId())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "Could not undelete book: %v", err)
+	}
+
+	return &bookiePb.UndeleteBookResponse{Book: restored}, nil
+}
+
+func (s *bookieService) WatchBooks(req *bookiePb.WatchBooksRequest, stream grpc.ServerStreamingServer[bookiePb.WatchBooksResponse]) error {
+	sub, err := s.broker.Subscribe(req.GetStartAfterRevision())
+	switch {
+	case errors.Is(err, events.ErrRevisionCompacted), errors.Is(err, events.ErrFutureRevision):
+		return status.Errorf(codes.OutOfRange, "Cannot resume from revision %d: %v; list books again and watch from the revision of the listing",
+			req.GetStartAfterRevision(), err)
+	case errors.Is(err, events.ErrClosed):
+		return status.Errorf(codes.Unavailable, "Server is shutting down")
+	case err != nil:
+		return status.Errorf(codes.Internal, "Could not watch books: %v", err)
+	}
+	defer sub.Close()
+
+	lastSent := req.GetStartAfterRevision()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-sub.Done():
+			if errors.Is(sub.Err(), events.ErrSlowConsumer) {
+				return status.Errorf(codes.ResourceExhausted,
+					"Watcher fell behind; resume with start_after_revision=%d", lastSent)
+			}
+			return status.Errorf(codes.Unavailable, "Server is shutting down")
+		case event := <-sub.Events():
+			if err := stream.Send(&bookiePb.WatchBooksResponse{Event: event}); err != nil {
+				return err
+			}
+			lastSent = event.GetRevision()
+		}
+	}
+}
+
+func (s *bookieService) BatchCreateBooks(ctx context.Context, req *bookiePb.BatchCreateBooksRequest) (*bookiePb.BatchCreateBooksResponse, error) {
+	items := req.GetRequests()
+	newBooks := make([]*bookiePb.Book, 0, len(items))
+	for _, item := range items {
+		id := item.GetId()
+		if id == "" {
+			generated, err := newBookID()
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "Could not generate book id: %v", err)
+			}
+			id = generated
+		}
+		newBooks = append(newBooks, &bookiePb.Book{
+			Id:          id,
+			Title:       item.GetTitle(),
+			Price:       item.GetPrice(),
+			Author:      item.GetAuthor(),
+			Description: item.GetDescription(),
+		})
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	err := s.repo.CreateMany(ctx, newBooks)
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return nil, status.Errorf(codes.AlreadyExists, "Could not create books: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not create books: %v", err)
+	}
+	for _, book := range newBooks {
+		s.broker.Publish(bookiePb.BookEvent_CREATED, book)
+	}
+
+	return &bookiePb.BatchCreateBooksResponse{Books: newBooks}, nil
+}
+
+func (s *bookieService) BatchGetBooks(ctx context.Context, req *bookiePb.BatchGetBooksRequest) (*bookiePb.BatchGetBooksResponse, error) {
+	res := &bookiePb.BatchGetBooksResponse{}
+	for _, id := range req.GetIds() {
+		book, err := s.repo.Get(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && isDeleted(book)) {
+			res.MissingIds = append(res.MissingIds, id)
+			continue
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Could not get book: %v", err)
+		}
+		res.Books = append(res.Books, book)
+	}
+	return res, nil
+}
+
+func (s *bookieService) ImportBooks(stream grpc.ClientStreamingServer[bookiePb.ImportBooksRequest, bookiePb.ImportBooksResponse]) error {
+	summary := &bookiePb.ImportBooksResponse{}
+	fail := func(row int64, reason string) {
+		summary.Failed++
+		if len(summary.Failures) < maxImportFailures {
+			summary.Failures = append(summary.Failures, &bookiePb.ImportBooksResponse_Failure{Row: row, Reason: reason})
+		}
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(summary)
+		}
+		if err != nil {
+			return err
+		}
+
+		item := req.GetBook()
+		if item == nil {
+			// Validate finds nothing wrong with a nil message.
+			fail(req.GetRow(), "book is required")
+			continue
+		}
+		if violations := validation.Validate(item); len(violations) > 0 {
+			reasons := make([]string, 0, len(violations))
+			for _, v := range violations {
+				reasons = append(reasons, v.GetDescription())
+			}
+			fail(req.GetRow(), strings.Join(reasons, "; "))
+			continue
+		}
+		id := item.GetId()
+		if id == "" {
+			if id, err = newBookID(); err != nil {
+				return status.Errorf(codes.Internal, "Could not generate book id: %v", err)
+			}
+		}
+
+		_, err = s.commit(bookiePb.BookEvent_CREATED, func() (*bookiePb.Book, error) {
+			return s.repo.Create(stream.Context(), &bookiePb.Book{
+				Id:          id,
+				Title:       item.GetTitle(),
+				Price:       item.GetPrice(),
+				Author:      item.GetAuthor(),
+				Description: item.GetDescription(),
+			})
+		})
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			summary.Skipped++
+			continue
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "Could not import row %d: %v", req.GetRow(), err)
+		}
+		summary.Created++
+	}
+}
+
+// commit runs write and publishes the book it returns as an event of
+// eventType, holding s.writeMu across both. Without it two writes to the same
+// book could be published in the opposite order to the one they were applied
+// in, and a client replaying the feed would end with stale state.
+func (s *bookieService) commit(eventType bookiePb.BookEvent_Type, write func() (*bookiePb.Book, error)) (*bookiePb.Book, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	book, err := write()
+	if err != nil {
+		return nil, err
+	}
+	s.broker.Publish(eventType, book)
+	return book, nil
+}
+
+// scanBooks iterates over the books that sort after after in order and pass
+// keep, or from the first book when after is nil. Books are read batchSize at
+// a time, resuming after the last book read, so only one batch of the
+// catalog is held in memory. Errors are yielded as gRPC statuses.
+func (s *bookieService) scanBooks(ctx context.Context, order query.Ordering, after *bookiePb.Book, batchSize int, keep func(*bookiePb.Book) bool) iter.Seq2[*bookiePb.Book, error] {
+	return func(yield func(*bookiePb.Book, error) bool) {
+		for {
+			batch, err := s.repo.ListPage(ctx, order, after, batchSize)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				yield(nil, status.FromContextError(ctxErr).Err())
+				return
+			}
+			if err != nil {
+				yield(nil, status.Errorf(codes.Internal, "Could not list books: %v", err))
+				return
+			}
+
+			for _, book := range batch {
+				if keep(book) && !yield(book, nil) {
+					return
+				}
+			}
+			if len(batch) < batchSize {
+				return
+			}
+			after = batch[len(batch)-1]
+		}
+	}
+}
+
+// countBooks returns the number of books a listing matches. Without a filter
+// it comes from the repository's counts; a filter can only be evaluated on
+// the books themselves, so they are scanned a batch at a time.
+func (s *bookieService) countBooks(ctx context.Context, showDeleted bool, filter *query.Filter) (int, error) {
+	if filter == nil {
+		live, deleted, err := s.repo.Count(ctx)
+		if err != nil {
+			return 0, status.Errorf(codes.Internal, "Could not count books: %v", err)
+		}
+		if showDeleted {
+			return live + deleted, nil
+		}
+		return live, nil
+	}
+
+	total := 0
+	for _, err := range s.scanBooks(ctx, query.Ordering{{Field: "id"}}, nil, maxChunkSize, listed(showDeleted, filter)) {
+		if err != nil {
+			return 0, err
+		}
+		total++
+	}
+	return total, nil
+}
+
+// parseListing parses the filter and order_by of a listing request.
+func parseListing(filterExpr, orderBy string) (*query.Filter, query.Ordering, error) {
+	filter, err := query.ParseFilter(filterExpr)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "Invalid filter: %v", err)
+	}
+	order, err := query.ParseOrderBy(orderBy)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "Invalid order_by: %v", err)
+	}
+	return filter, order, nil
+}
+
+// getLiveBook loads a book and converts storage errors to gRPC statuses.
+// Soft-deleted books are treated as missing.
+func (s *bookieService) getLiveBook(ctx context.Context, id string) (*bookiePb.Book, error) {
+	book, err := s.repo.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && isDeleted(book)) {
+		return nil, status.Errorf(codes.NotFound, "Book with ID %s not found", id)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not get book: %v", err)
+	}
+	return book, nil
+}
+
+// listed returns whether a book belongs in a listing with the given
+// show_deleted flag and filter.
+func listed(showDeleted bool, filter *query.Filter) func(*bookiePb.Book) bool {
+	return func(book *bookiePb.Book) bool {
+		return (showDeleted || !isDeleted(book)) && filter.Match(book)
+	}
+}
+
+func isDeleted(book *bookiePb.Book) bool {
+	return book.GetDeleteTime() != nil
+}
+
+// resolvePageSize applies the default and maximum to the requested page size.
+// The deprecated perPage field is honoured when page_size is not set.
+func resolvePageSize(req *bookiePb.ListBookRequest) int {
+	size := req.GetPageSize()
+	if size == 0 {
+		size = req.GetPerPage() //nolint:staticcheck // kept for older clients
+	}
+	switch {
+	case size <= 0:
+		return defaultPageSize
+	case size > maxPageSize:
+		return maxPageSize
+	default:
+		return int(size)
+	}
+}
+
+// resolveChunkSize applies the default and maximum to a StreamBooks chunk size.
+func resolveChunkSize(size int32) int {
+	switch {
+	case size <= 0:
+		return defaultChunkSize
+	case size > maxChunkSize:
+		return maxChunkSize
+	default:
+		return int(size)
+	}
+}
@@ -0,0 +1,553 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/events"
+	"github.com/sadhakbj/bookie-grpc/src/internal/repository"
+)
+
+// newTestClient serves svc over an in-memory bufconn listener and returns a
+// client connected to it with opts. Everything is torn down when the test ends.
+func newTestClient(t *testing.T, svc bookiePb.BookieServer, opts ...grpc.DialOption) bookiePb.BookieClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(serverOptions()...)
+	bookiePb.RegisterBookieServer(server, svc)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	opts = append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return bookiePb.NewBookieClient(conn)
+}
+
+// TestConcurrentRPCs fires parallel Create, List and Get calls against the
+// in-memory store. Run with -race to catch unsynchronised access.
+func TestConcurrentRPCs(t *testing.T) {
+	repo := repository.NewMemoryRepository(repository.SeedBooks())
+	client := newTestClient(t, newBookieService(repo, events.NewBroker(16, 16)))
+	ctx := context.Background()
+
+	const workers, perWorker = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker*3)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				created, err := client.CreateBook(ctx, &bookiePb.CreateBookRequest{
+					Title: fmt.Sprintf("book %d-%d", w, i),
+					Price: int64(i),
+				})
+				if err != nil {
+					errs <- fmt.Errorf("create: %w", err)
+					continue
+				}
+				if _, err := client.ListBooks(ctx, &bookiePb.ListBookRequest{}); err != nil {
+					errs <- fmt.Errorf("list: %w", err)
+				}
+				if _, err := client.GetByID(ctx, &bookiePb.GetByIDRequest{Id: created.GetBook().GetId()}); err != nil {
+					errs <- fmt.Errorf("get: %w", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	res, err := client.ListBooks(ctx, &bookiePb.ListBookRequest{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := len(repository.SeedBooks()) + workers*perWorker
+	if got := int(res.GetTotalSize()); got != want {
+		t.Errorf("got %d books, want %d", got, want)
+	}
+}
+
+// TestValidationInterceptors checks that invalid requests are rejected with
+// field violations before reaching the service, for unary and streaming RPCs.
+func TestValidationInterceptors(t *testing.T) {
+	repo := repository.NewMemoryRepository(repository.SeedBooks())
+	client := newTestClient(t, newBookieService(repo, events.NewBroker(16, 16)))
+	ctx := context.Background()
+
+	_, err := client.CreateBook(ctx, &bookiePb.CreateBookRequest{Price: -10})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("CreateBook code = %s, want InvalidArgument", st.Code())
+	}
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	if want := []string{"title", "price"}; !slices.Equal(fields, want) {
+		t.Errorf("CreateBook violations = %v, want %v", fields, want)
+	}
+
+	books, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(books) != len(repository.SeedBooks()) {
+		t.Errorf("invalid CreateBook stored a book: %d books", len(books))
+	}
+
+	stream, err := client.WatchBooks(ctx, &bookiePb.WatchBooksRequest{StartAfterRevision: -1})
+	if err != nil {
+		t.Fatalf("WatchBooks: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("WatchBooks Recv error = %v, want InvalidArgument", err)
+	}
+}
+
+// TestTracing checks that the trace of the caller continues on the server
+// and that repository calls are children of the RPC span.
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	defaultProvider, defaultPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(defaultProvider)
+		otel.SetTextMapPropagator(defaultPropagator)
+	}()
+
+	repo := repository.NewTracedRepository(repository.NewMemoryRepository(repository.SeedBooks()), "memory")
+	client := newTestClient(t, newBookieService(repo, events.NewBroker(16, 16)),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "GET /books/{id}")
+	if _, err := client.GetByID(ctx, &bookiePb.GetByIDRequest{Id: "1234"}); err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	root.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()+"/"+span.SpanKind().String()] = span
+	}
+	clientSpan := spans["Bookie/GetByID/client"]
+	serverSpan := spans["Bookie/GetByID/server"]
+	repoSpan := spans["BookRepository.Get/client"]
+	if clientSpan == nil || serverSpan == nil || repoSpan == nil {
+		t.Fatalf("recorded spans %v, want client, server and repository spans", slices.Collect(maps.Keys(spans)))
+	}
+
+	for _, span := range []sdktrace.ReadOnlySpan{clientSpan, serverSpan, repoSpan} {
+		if span.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			t.Errorf("span %s is not part of the caller's trace", span.Name())
+		}
+	}
+	if serverSpan.Parent().SpanID() != clientSpan.SpanContext().SpanID() {
+		t.Errorf("server span is not a child of the client span")
+	}
+	if repoSpan.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+		t.Errorf("repository span is not a child of the server span")
+	}
+}
+
+// testRepositories returns a fresh store of every backend, seeded with the
+// sample catalog.
+func testRepositories(t *testing.T) map[string]repository.BookRepository {
+	t.Helper()
+
+	sqlite, err := repository.NewSQLiteRepository(context.Background(), filepath.Join(t.TempDir(), "bookie.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlite.Close() })
+	if err := sqlite.CreateMany(context.Background(), repository.SeedBooks()); err != nil {
+		t.Fatalf("seed sqlite: %v", err)
+	}
+
+	return map[string]repository.BookRepository{
+		"memory": repository.NewMemoryRepository(repository.SeedBooks()),
+		"sqlite": sqlite,
+	}
+}
+
+func TestUpdateDeleteUndelete(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, newBookieService(repo, events.NewBroker(16, 16)))
+			ctx := context.Background()
+
+			updated, err := client.UpdateBook(ctx, &bookiePb.UpdateBookRequest{
+				Book:       &bookiePb.Book{Id: "1234", Title: "ignored", Price: 150},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"price"}},
+			})
+			if err != nil {
+				t.Fatalf("UpdateBook: %v", err)
+			}
+			if b := updated.GetBook(); b.GetTitle() != "Harry Potter" || b.GetPrice() != 150 {
+				t.Errorf("UpdateBook = %v, want only the price changed", b)
+			}
+
+			_, err = client.UndeleteBook(ctx, &bookiePb.UndeleteBookRequest{Id: "1234"})
+			if code := status.Code(err); code != codes.FailedPrecondition {
+				t.Errorf("UndeleteBook of a live book: code = %s, want FailedPrecondition", code)
+			}
+
+			deleted, err := client.DeleteBook(ctx, &bookiePb.DeleteBookRequest{Id: "1234"})
+			if err != nil {
+				t.Fatalf("DeleteBook: %v", err)
+			}
+			if deleted.GetBook().GetDeleteTime() == nil {
+				t.Error("DeleteBook returned a book without delete_time")
+			}
+
+			wantNotFound := func(rpc string, err error) {
+				t.Helper()
+				if code := status.Code(err); code != codes.NotFound {
+					t.Errorf("%s: code = %s, want NotFound", rpc, code)
+				}
+			}
+			_, err = client.DeleteBook(ctx, &bookiePb.DeleteBookRequest{Id: "1234"})
+			wantNotFound("DeleteBook of a deleted book", err)
+			_, err = client.UpdateBook(ctx, &bookiePb.UpdateBookRequest{Book: &bookiePb.Book{Id: "1234", Title: "New"}})
+			wantNotFound("UpdateBook of a deleted book", err)
+			_, err = client.GetByID(ctx, &bookiePb.GetByIDRequest{Id: "1234"})
+			wantNotFound("GetByID of a deleted book", err)
+			_, err = client.UndeleteBook(ctx, &bookiePb.UndeleteBookRequest{Id: "missing"})
+			wantNotFound("UndeleteBook of a missing book", err)
+
+			restored, err := client.UndeleteBook(ctx, &bookiePb.UndeleteBookRequest{Id: "1234"})
+			if err != nil {
+				t.Fatalf("UndeleteBook: %v", err)
+			}
+			if b := restored.GetBook(); b.GetDeleteTime() != nil || b.GetPrice() != 150 {
+				t.Errorf("UndeleteBook = %v, want the live book with its update", b)
+			}
+		})
+	}
+}
+
+// TestConcurrentUpdates updates different fields of one book in parallel.
+// Every write must survive: none may overwrite the others with stale values.
+func TestConcurrentUpdates(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, newBookieService(repo, events.NewBroker(64, 64)))
+			ctx := context.Background()
+
+			updates := []*bookiePb.UpdateBookRequest{
+				{Book: &bookiePb.Book{Id: "1234", Title: "New title"}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}}},
+				{Book: &bookiePb.Book{Id: "1234", Author: "New author"}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"author"}}},
+				{Book: &bookiePb.Book{Id: "1234", Description: "New description"}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"description"}}},
+				{Book: &bookiePb.Book{Id: "1234", Price: 999}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"price"}}},
+			}
+			var wg sync.WaitGroup
+			for _, req := range updates {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := client.UpdateBook(ctx, req); err != nil {
+						t.Errorf("UpdateBook %v: %v", req.GetUpdateMask().GetPaths(), err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			res, err := client.GetByID(ctx, &bookiePb.GetByIDRequest{Id: "1234"})
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			want := &bookiePb.Book{Id: "1234", Title: "New title", Author: "New author", Description: "New description", Price: 999}
+			if !proto.Equal(res.GetBook(), want) {
+				t.Errorf("book = %v, want %v", res.GetBook(), want)
+			}
+		})
+	}
+}
+
+// TestEventsFollowWriteOrder races updates of one book and checks that the
+// last event published for it carries the state that was stored last.
+func TestEventsFollowWriteOrder(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			const writers = 16
+			broker := events.NewBroker(writers, writers)
+			client := newTestClient(t, newBookieService(repo, broker))
+			ctx := context.Background()
+
+			sub, err := broker.Subscribe(0)
+			if err != nil {
+				t.Fatalf("Subscribe: %v", err)
+			}
+			defer sub.Close()
+
+			var wg sync.WaitGroup
+			for i := range writers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := client.UpdateBook(ctx, &bookiePb.UpdateBookRequest{
+						Book:       &bookiePb.Book{Id: "1234", Price: int64(i)},
+						UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"price"}},
+					})
+					if err != nil {
+						t.Errorf("UpdateBook: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			var last *bookiePb.BookEvent
+			for range writers {
+				last = <-sub.Events()
+			}
+			stored, err := repo.Get(ctx, "1234")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if got, want := last.GetBook().GetPrice(), stored.GetPrice(); got != want {
+				t.Errorf("last event has price %d, stored book has %d", got, want)
+			}
+		})
+	}
+}
+
+// TestListBooks pages through a filtered listing while books are added on
+// both sides of the cursor.
+func TestListBooks(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, newBookieService(repo, events.NewBroker(16, 16)))
+			ctx := context.Background()
+
+			create := func(id string, price int64) {
+				t.Helper()
+				_, err := client.CreateBook(ctx, &bookiePb.CreateBookRequest{Id: id, Title: "Book " + id, Price: price})
+				if err != nil {
+					t.Fatalf("CreateBook: %v", err)
+				}
+			}
+			for i := range 7 {
+				create(fmt.Sprintf("b%d", i), int64(i%4*100))
+			}
+			if _, err := client.DeleteBook(ctx, &bookiePb.DeleteBookRequest{Id: "b3"}); err != nil {
+				t.Fatalf("DeleteBook: %v", err)
+			}
+
+			req := &bookiePb.ListBookRequest{Filter: `title : "Book"`, OrderBy: "price desc", PageSize: 2}
+			var pages [][]string
+			for {
+				res, err := client.ListBooks(ctx, req)
+				if err != nil {
+					t.Fatalf("ListBooks: %v", err)
+				}
+				var ids []string
+				for _, book := range res.GetBooks() {
+					ids = append(ids, book.GetId())
+				}
+				pages = append(pages, ids)
+				if len(pages) == 1 {
+					if got := res.GetTotalSize(); got != 6 {
+						t.Errorf("total_size = %d, want 6", got)
+					}
+					// b7 sorts before the cursor and b8 after it.
+					create("b7", 300)
+					create("b8", 0)
+				}
+				if res.GetNextPageToken() == "" {
+					break
+				}
+				req.PageToken = res.GetNextPageToken()
+			}
+			want := [][]string{{"b2", "b6"}, {"b1", "b5"}, {"b0", "b4"}, {"b8"}}
+			if !slices.EqualFunc(pages, want, slices.Equal) {
+				t.Errorf("pages = %v, want %v", pages, want)
+			}
+
+			for _, showDeleted := range []bool{false, true} {
+				res, err := client.ListBooks(ctx, &bookiePb.ListBookRequest{ShowDeleted: showDeleted})
+				if err != nil {
+					t.Fatalf("ListBooks: %v", err)
+				}
+				want := len(repository.SeedBooks()) + 8
+				if showDeleted {
+					want++
+				}
+				if got := int(res.GetTotalSize()); got != want {
+					t.Errorf("show_deleted=%t: total_size = %d, want %d", showDeleted, got, want)
+				}
+			}
+
+			req.Filter = `title : "Other"`
+			if _, err := client.ListBooks(ctx, req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("ListBooks with a token of another filter: %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
+
+// TestListThenWatch checks that watching from the revision of a listing
+// delivers a change made between the two calls.
+func TestListThenWatch(t *testing.T) {
+	repo := repository.NewMemoryRepository(repository.SeedBooks())
+	client := newTestClient(t, newBookieService(repo, events.NewBroker(16, 16)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := client.CreateBook(ctx, &bookiePb.CreateBookRequest{Id: "before", Title: "Before"}); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	listed, err := client.ListBooks(ctx, &bookiePb.ListBookRequest{})
+	if err != nil {
+		t.Fatalf("ListBooks: %v", err)
+	}
+	if got := listed.GetRevision(); got != 1 {
+		t.Errorf("revision = %d, want 1", got)
+	}
+	if _, err := client.CreateBook(ctx, &bookiePb.CreateBookRequest{Id: "between", Title: "Between"}); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+
+	stream, err := client.WatchBooks(ctx, &bookiePb.WatchBooksRequest{StartAfterRevision: listed.GetRevision()})
+	if err != nil {
+		t.Fatalf("WatchBooks: %v", err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if got := res.GetEvent().GetBook().GetId(); got != "between" {
+		t.Errorf("first event is for book %q, want \"between\"", got)
+	}
+}
+
+func TestStreamBooks(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, newBookieService(repo, events.NewBroker(16, 16)))
+			ctx := context.Background()
+
+			for i := range 7 {
+				_, err := client.CreateBook(ctx, &bookiePb.CreateBookRequest{
+					Id:    fmt.Sprintf("b%d", i),
+					Title: fmt.Sprintf("Book %d", i),
+					Price: int64(i % 4 * 100),
+				})
+				if err != nil {
+					t.Fatalf("CreateBook: %v", err)
+				}
+			}
+			if _, err := client.DeleteBook(ctx, &bookiePb.DeleteBookRequest{Id: "b3"}); err != nil {
+				t.Fatalf("DeleteBook: %v", err)
+			}
+
+			stream, err := client.StreamBooks(ctx, &bookiePb.StreamBooksRequest{
+				Filter:    `title : "Book"`,
+				OrderBy:   "price desc",
+				ChunkSize: 2,
+			})
+			if err != nil {
+				t.Fatalf("StreamBooks: %v", err)
+			}
+			var ids []string
+			var sizes []int
+			for {
+				res, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Recv: %v", err)
+				}
+				sizes = append(sizes, len(res.GetBooks()))
+				for _, book := range res.GetBooks() {
+					ids = append(ids, book.GetId())
+				}
+			}
+
+			if want := []string{"b2", "b6", "b1", "b5", "b0", "b4"}; !slices.Equal(ids, want) {
+				t.Errorf("streamed %v, want %v", ids, want)
+			}
+			if want := []int{2, 2, 2}; !slices.Equal(sizes, want) {
+				t.Errorf("chunk sizes = %v, want %v", sizes, want)
+			}
+		})
+	}
+}
+
+// TestImportBooksMissingBook sends rows without a book, which the validation
+// of the row message alone does not catch.
+func TestImportBooksMissingBook(t *testing.T) {
+	repo := repository.NewMemoryRepository(nil)
+	client := newTestClient(t, newBookieService(repo, events.NewBroker(16, 16)))
+	ctx := context.Background()
+
+	stream, err := client.ImportBooks(ctx)
+	if err != nil {
+		t.Fatalf("ImportBooks: %v", err)
+	}
+	for _, req := range []*bookiePb.ImportBooksRequest{
+		{Row: 2},
+		{Row: 3, Book: &bookiePb.CreateBookRequest{Title: "Dune"}},
+	} {
+		if err := stream.Send(req); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	summary, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("CloseAndRecv: %v", err)
+	}
+
+	if summary.GetCreated() != 1 || summary.GetFailed() != 1 {
+		t.Errorf("summary = %v, want 1 created and 1 failed", summary)
+	}
+	if f := summary.GetFailures(); len(f) != 1 || f[0].GetRow() != 2 || f[0].GetReason() != "book is required" {
+		t.Errorf("failures = %v, want row 2 with reason %q", f, "book is required")
+	}
+	if books, _ := repo.List(ctx); len(books) != 1 || books[0].GetTitle() != "Dune" {
+		t.Errorf("stored books = %v, want only Dune", books)
+	}
+}
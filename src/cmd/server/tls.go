@@ -0,0 +1,30 @@
+package main
+
+import (
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/config"
+	"github.com/sadhakbj/bookie-grpc/src/internal/tlsconfig"
+)
+
+// serverCredentials returns the transport credentials of the gRPC listener
+// and, when TLS is on, the reloader that keeps its certificates current.
+func serverCredentials(cfg config.ServerTLS) (credentials.TransportCredentials, *tlsconfig.Reloader, error) {
+	if !cfg.Enabled() {
+		return insecure.NewCredentials(), nil, nil
+	}
+	reloader, err := tlsconfig.NewReloader(tlsconfig.Files{
+		CertFile: cfg.CertFile,
+		KeyFile:  cfg.KeyFile,
+		CAFile:   cfg.ClientCAFile,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsConfig, err := tlsconfig.Server(reloader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentials.NewTLS(tlsConfig), reloader, nil
+}
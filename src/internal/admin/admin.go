@@ -0,0 +1,70 @@
+// Package admin serves the operational endpoints of the bookie binaries on a
+// listener separate from the public API.
+package admin
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/reflection"
+)
+
+// Options configures the admin server.
+type Options struct {
+	// Debug adds the debug endpoints: pprof under /debug/pprof/, a dump of
+	// Config on /debug/config and the gRPC channelz service. They expose
+	// internals of the process, so Debug must stay off in production.
+	Debug bool
+	// Config is the runtime configuration dumped on /debug/config.
+	Config any
+}
+
+// NewServer returns the admin HTTP server for port. It always exposes the
+// metrics of the default Prometheus registry on /metrics.
+//
+// With opts.Debug the listener also speaks plaintext HTTP/2 and serves the
+// channelz and reflection gRPC services, so tools such as grpcdebug and
+// grpcurl can inspect the connections of the process, e.g.
+// "grpcdebug localhost:9020 channelz servers".
+func NewServer(port string, opts Options) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if !opts.Debug {
+		return server
+	}
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("GET /debug/config", configHandler(opts.Config))
+
+	grpcServer := grpc.NewServer()
+	channelzservice.RegisterChannelzServiceToServer(grpcServer)
+	reflection.Register(grpcServer)
+
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ProtoMajor == 2 && strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, req)
+			return
+		}
+		mux.ServeHTTP(w, req)
+	})
+	server.Protocols = new(http.Protocols)
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetUnencryptedHTTP2(true)
+	server.RegisterOnShutdown(grpcServer.Stop)
+	return server
+}
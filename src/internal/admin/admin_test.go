@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type testConfig struct {
+	Port     string
+	Timeout  time.Duration
+	Routes   map[string]time.Duration
+	Secrets  map[string]string `redact:"true"`
+	Empty    string            `redact:"true"`
+	internal string
+}
+
+// serve starts an admin server with opts on a free port and returns its address.
+func serve(t *testing.T, opts Options) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := NewServer("0", opts)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+	return listener.Addr().String()
+}
+
+func get(t *testing.T, url string) *http.Response {
+	t.Helper()
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	t.Cleanup(func() { _ = res.Body.Close() })
+	return res
+}
+
+func TestDebugDisabled(t *testing.T) {
+	addr := serve(t, Options{Config: testConfig{Port: "8020"}})
+
+	if res := get(t, "http://"+addr+"/metrics"); res.StatusCode != http.StatusOK {
+		t.Errorf("/metrics status = %d, want 200", res.StatusCode)
+	}
+	for _, path := range []string{"/debug/config", "/debug/pprof/"} {
+		if res := get(t, "http://"+addr+path); res.StatusCode != http.StatusNotFound {
+			t.Errorf("%s status = %d, want 404 with debug off", path, res.StatusCode)
+		}
+	}
+}
+
+func TestDebugEnabled(t *testing.T) {
+	cfg := testConfig{Port: "8020", Timeout: 10 * time.Second, Routes: map[string]time.Duration{"GET /books": 0},
+		Secrets: map[string]string{"ci": "9f86d0"}, internal: "x"}
+	addr := serve(t, Options{Debug: true, Config: cfg})
+
+	if res := get(t, "http://"+addr+"/debug/pprof/"); res.StatusCode != http.StatusOK {
+		t.Errorf("/debug/pprof/ status = %d, want 200", res.StatusCode)
+	}
+
+	var dump struct {
+		Config    map[string]any `json:"config"`
+		GoVersion string         `json:"go_version"`
+	}
+	if err := json.NewDecoder(get(t, "http://"+addr+"/debug/config").Body).Decode(&dump); err != nil {
+		t.Fatalf("decode /debug/config: %v", err)
+	}
+	if dump.GoVersion == "" || dump.Config["Port"] != "8020" || dump.Config["Timeout"] != "10s" {
+		t.Errorf("dump = %+v", dump)
+	}
+	if routes, _ := dump.Config["Routes"].(map[string]any); routes["GET /books"] != "0s" {
+		t.Errorf("Routes = %v, want durations as strings", dump.Config["Routes"])
+	}
+	if dump.Config["Secrets"] != redacted || dump.Config["Empty"] != "" {
+		t.Errorf("Secrets = %v, Empty = %v, want only set fields redacted", dump.Config["Secrets"], dump.Config["Empty"])
+	}
+	if _, ok := dump.Config["internal"]; ok {
+		t.Errorf("unexported field dumped: %v", dump.Config)
+	}
+
+	// channelz is served over plaintext HTTP/2 on the same port.
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := channelzpb.NewChannelzClient(conn).GetTopChannels(ctx, &channelzpb.GetTopChannelsRequest{})
+	if err != nil {
+		t.Fatalf("GetTopChannels: %v", err)
+	}
+	if len(res.GetChannel()) == 0 {
+		t.Errorf("channelz reports no channels, want at least this test's")
+	}
+}
@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// startTime is when the process started, as reported by /debug/config.
+var startTime = time.Now()
+
+// configHandler serves cfg as JSON together with facts about the running
+// binary. Durations are written in Go syntax, e.g. "10s". Fields tagged
+// `redact:"true"` are replaced by a placeholder unless they are empty.
+func configHandler(cfg any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		dump := map[string]any{
+			"config":     jsonValue(reflect.ValueOf(cfg)),
+			"go_version": runtime.Version(),
+			"gomaxprocs": runtime.GOMAXPROCS(0),
+			"goroutines": runtime.NumGoroutine(),
+			"pid":        os.Getpid(),
+			"uptime":     time.Since(startTime).Round(time.Second).String(),
+		}
+		if info, ok := debug.ReadBuildInfo(); ok {
+			dump["module_version"] = info.Main.Version
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(dump)
+	})
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// redacted stands in for the value of a field tagged `redact:"true"`.
+const redacted = "[redacted]"
+
+// jsonValue converts v to something encoding/json writes readably: structs
+// become objects keyed by field name and durations become strings.
+func jsonValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonValue(v.Elem())
+	case reflect.Struct:
+		fields := make(map[string]any, v.NumField())
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			switch {
+			case !field.IsExported():
+			case field.Tag.Get("redact") == "true" && !isEmpty(v.Field(i)):
+				fields[field.Name] = redacted
+			default:
+				fields[field.Name] = jsonValue(v.Field(i))
+			}
+		}
+		return fields
+	case reflect.Map:
+		entries := make(map[string]any, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			entries[it.Key().String()] = jsonValue(it.Value())
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = jsonValue(v.Index(i))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
+
+// isEmpty reports whether v is the zero value or has no elements.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// apiKey is a named API key, known only by its SHA-256 digest.
+type apiKey struct {
+	name string
+	hash [sha256.Size]byte
+}
+
+// APIKeys checks static API keys against their SHA-256 digests, so the
+// configuration never holds the keys themselves.
+type APIKeys struct {
+	keys []apiKey
+}
+
+// NewAPIKeys creates APIKeys from hashes, which maps the name of each key to
+// the hex SHA-256 digest of the key. Names become principal subjects.
+func NewAPIKeys(hashes map[string]string) (*APIKeys, error) {
+	keys := make([]apiKey, 0, len(hashes))
+	for name, digest := range hashes {
+		if !ValidSubject(name) {
+			return nil, fmt.Errorf("invalid API key name %q", name)
+		}
+		raw, err := hex.DecodeString(digest)
+		if err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: hash must be 64 hex digits of SHA-256", name)
+		}
+		key := apiKey{name: name}
+		copy(key.hash[:], raw)
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].name < keys[j].name })
+	return &APIKeys{keys: keys}, nil
+}
+
+// Authenticate returns the principal of key. Every configured digest is
+// compared in constant time, so timing does not reveal which one matched.
+func (k *APIKeys) Authenticate(key string) (Principal, error) {
+	hash := sha256.Sum256([]byte(key))
+	match := -1
+	for i := range k.keys {
+		if subtle.ConstantTimeCompare(hash[:], k.keys[i].hash[:]) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return Principal{}, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+	return Principal{Subject: k.keys[match].name, Method: MethodAPIKey}, nil
+}
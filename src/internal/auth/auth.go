@@ -0,0 +1,132 @@
+// Package auth authenticates callers of the HTTP client (BFF) with static API
+// keys or JWT bearer tokens and carries the authenticated principal to the
+// gRPC server, where authorization decisions can use it.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	// APIKeyHeader is the HTTP header that carries an API key.
+	APIKeyHeader = "X-API-Key"
+	// SubjectMetadataKey is the gRPC metadata key that carries the subject of
+	// the principal.
+	SubjectMetadataKey = "x-bookie-principal"
+	// MethodMetadataKey is the gRPC metadata key that carries how the
+	// principal authenticated.
+	MethodMetadataKey = "x-bookie-auth-method"
+	// LogKey is the attribute name of the principal in log lines.
+	LogKey = "principal"
+	// maxSubjectLength bounds subjects forwarded as metadata.
+	maxSubjectLength = 256
+)
+
+// Ways a principal can authenticate.
+const (
+	MethodAPIKey = "api-key"
+	MethodJWT    = "jwt"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries neither an API key
+	// nor a bearer token.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when the credentials of a request are
+	// unknown, malformed or expired.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject names the caller: the name of its API key or the "sub" claim
+	// of its token.
+	Subject string
+	// Method is MethodAPIKey or MethodJWT.
+	Method string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx and whether there is one.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
+
+// Attr returns the log attribute for p.
+func Attr(p Principal) slog.Attr {
+	return slog.String(LogKey, p.Method+":"+p.Subject)
+}
+
+// ValidSubject reports whether subject can be forwarded as gRPC metadata,
+// which only allows printable ASCII.
+func ValidSubject(subject string) bool {
+	if subject == "" || len(subject) > maxSubjectLength {
+		return false
+	}
+	for i := 0; i < len(subject); i++ {
+		if subject[i] < ' ' || subject[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Authenticator checks the credentials of HTTP requests against the
+// configured API keys and token verifier. Either may be nil.
+type Authenticator struct {
+	keys  *APIKeys
+	token *TokenVerifier
+	realm string
+}
+
+// NewAuthenticator creates an Authenticator accepting keys and tokens
+// verified by token. realm names the protected API in challenges.
+func NewAuthenticator(keys *APIKeys, token *TokenVerifier, realm string) *Authenticator {
+	return &Authenticator{keys: keys, token: token, realm: realm}
+}
+
+// Authenticate returns the principal of req. An API key in APIKeyHeader is
+// checked first, then a bearer token in the Authorization header. Failures
+// wrap ErrNoCredentials or ErrInvalidCredentials.
+func (a *Authenticator) Authenticate(req *http.Request) (Principal, error) {
+	if key := req.Header.Get(APIKeyHeader); key != "" && a.keys != nil {
+		return a.keys.Authenticate(key)
+	}
+	if authorization := req.Header.Get("Authorization"); authorization != "" && a.token != nil {
+		scheme, token, ok := strings.Cut(authorization, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return Principal{}, ErrNoCredentials
+		}
+		return a.token.Verify(strings.TrimSpace(token))
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+// Challenges returns the WWW-Authenticate values of a 401 response to a
+// request that failed with err, one per accepted scheme. Rejected bearer
+// tokens are flagged as in RFC 6750.
+func (a *Authenticator) Challenges(err error) []string {
+	var challenges []string
+	if a.token != nil {
+		challenge := `Bearer realm="` + a.realm + `"`
+		if errors.Is(err, ErrInvalidCredentials) {
+			challenge += `, error="invalid_token"`
+		}
+		challenges = append(challenges, challenge)
+	}
+	if a.keys != nil {
+		challenges = append(challenges, `APIKey realm="`+a.realm+`", header="`+APIKeyHeader+`"`)
+	}
+	return challenges
+}
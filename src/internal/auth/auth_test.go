@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestValidSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    bool
+	}{
+		{"ci", true},
+		{"auth0|5f7c8ec7", true},
+		{"Jane Doe", true},
+		{"", false},
+		{"new\nline", false},
+		{"josé", false},
+		{strings.Repeat("a", maxSubjectLength), true},
+		{strings.Repeat("a", maxSubjectLength+1), false},
+	}
+	for _, tt := range tests {
+		if got := ValidSubject(tt.subject); got != tt.want {
+			t.Errorf("ValidSubject(%q) = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestNewAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		hashes  map[string]string
+		wantErr bool
+	}{
+		{name: "valid", hashes: map[string]string{"ci": hashKey("secret")}},
+		{name: "not hex", hashes: map[string]string{"ci": "secret"}, wantErr: true},
+		{name: "wrong length", hashes: map[string]string{"ci": hashKey("secret")[:32]}, wantErr: true},
+		{name: "invalid name", hashes: map[string]string{"bad\nname": hashKey("secret")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAPIKeys(tt.hashes); (err != nil) != tt.wantErr {
+				t.Errorf("NewAPIKeys error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	keys, err := NewAPIKeys(map[string]string{"ci": hashKey("ci-secret"), "ops": hashKey("ops-secret")})
+	if err != nil {
+		t.Fatalf("NewAPIKeys: %v", err)
+	}
+	signer := newSigner(t)
+	verifier := signer.verifier(t, "", "")
+
+	tests := []struct {
+		name          string
+		authn         *Authenticator
+		headers       map[string]string
+		want          Principal
+		wantErr       error
+		wantChallenge string
+	}{
+		{
+			name:    "api key",
+			authn:   NewAuthenticator(keys, nil, "bookie"),
+			headers: map[string]string{APIKeyHeader: "ops-secret"},
+			want:    Principal{Subject: "ops", Method: MethodAPIKey},
+		},
+		{
+			name:          "unknown api key",
+			authn:         NewAuthenticator(keys, nil, "bookie"),
+			headers:       map[string]string{APIKeyHeader: "guess"},
+			wantErr:       ErrInvalidCredentials,
+			wantChallenge: `APIKey realm="bookie", header="X-API-Key"`,
+		},
+		{
+			name:    "bearer token",
+			authn:   NewAuthenticator(keys, verifier, "bookie"),
+			headers: map[string]string{"Authorization": "Bearer " + signer.sign(t, validClaims())},
+			want:    Principal{Subject: "user-1", Method: MethodJWT},
+		},
+		{
+			name:          "invalid bearer token",
+			authn:         NewAuthenticator(keys, verifier, "bookie"),
+			headers:       map[string]string{"Authorization": "Bearer nonsense"},
+			wantErr:       ErrInvalidCredentials,
+			wantChallenge: `Bearer realm="bookie", error="invalid_token"`,
+		},
+		{
+			name:          "no credentials",
+			authn:         NewAuthenticator(keys, verifier, "bookie"),
+			wantErr:       ErrNoCredentials,
+			wantChallenge: `Bearer realm="bookie"`,
+		},
+		{
+			name:          "basic auth is not accepted",
+			authn:         NewAuthenticator(keys, verifier, "bookie"),
+			headers:       map[string]string{"Authorization": "Basic Y2k6c2VjcmV0"},
+			wantErr:       ErrNoCredentials,
+			wantChallenge: `Bearer realm="bookie"`,
+		},
+		{
+			name:          "bearer token without a verifier",
+			authn:         NewAuthenticator(keys, nil, "bookie"),
+			headers:       map[string]string{"Authorization": "Bearer " + signer.sign(t, validClaims())},
+			wantErr:       ErrNoCredentials,
+			wantChallenge: `APIKey realm="bookie", header="X-API-Key"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/books", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			got, err := tt.authn.Authenticate(req)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Authenticate error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Authenticate = %+v, want %+v", got, tt.want)
+			}
+			if err == nil {
+				return
+			}
+			if challenges := tt.authn.Challenges(err); challenges[0] != tt.wantChallenge {
+				t.Errorf("Challenges = %q, want first %q", challenges, tt.wantChallenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// UnaryClientInterceptor forwards the principal of the call context as
+// outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+func outgoing(ctx context.Context) context.Context {
+	if p, ok := FromContext(ctx); ok {
+		return metadata.AppendToOutgoingContext(ctx, SubjectMetadataKey, p.Subject, MethodMetadataKey, p.Method)
+	}
+	return ctx
+}
+
+// UnaryServerInterceptor stores the principal sent by the BFF in the context,
+// where handlers can authorize on it, and adds it to the context logger. The
+// metadata is only trusted from callers that presented a client certificate
+// the TLS handshake verified, so it requires mutual TLS (TLS_CLIENT_CA_FILE);
+// without it every call is anonymous.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(incoming(ctx), req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, utils.ServerStreamWithContext(incoming(ss.Context()), ss))
+	}
+}
+
+func incoming(ctx context.Context) context.Context {
+	if !verifiedPeer(ctx) {
+		return ctx
+	}
+	subjects := metadata.ValueFromIncomingContext(ctx, SubjectMetadataKey)
+	methods := metadata.ValueFromIncomingContext(ctx, MethodMetadataKey)
+	if len(subjects) == 0 || len(methods) == 0 || !ValidSubject(subjects[0]) {
+		return ctx
+	}
+	p := Principal{Subject: subjects[0], Method: methods[0]}
+	if p.Method != MethodAPIKey && p.Method != MethodJWT {
+		return ctx
+	}
+	ctx = NewContext(ctx, p)
+	return utils.ContextWithLogger(ctx, utils.LoggerFromContext(ctx).With(Attr(p)))
+}
+
+// verifiedPeer reports whether the caller presented a client certificate that
+// chains to a trusted CA. Anyone who can reach the port can send metadata, so
+// only such callers may claim a principal.
+func verifiedPeer(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	return ok && len(info.State.VerifiedChains) > 0
+}
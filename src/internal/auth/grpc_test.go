@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/test/bufconn"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// recordingServer remembers the principal of the last GetByID call and logs
+// a line with the context logger.
+type recordingServer struct {
+	bookiePb.UnimplementedBookieServer
+	principal Principal
+	ok        bool
+}
+
+func (s *recordingServer) GetByID(ctx context.Context, _ *bookiePb.GetByIDRequest) (*bookiePb.GetByIDResponse, error) {
+	s.principal, s.ok = FromContext(ctx)
+	utils.LoggerFromContext(ctx).Info("handled")
+	return &bookiePb.GetByIDResponse{}, nil
+}
+
+func TestPropagation(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	// bufconn has no TLS, so the peer's client certificate is faked in front
+	// of the interceptor under test.
+	var verified atomic.Bool
+	fakePeer := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if verified.Load() {
+			ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{
+				State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}},
+			}})
+		}
+		return handler(ctx, req)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(fakePeer, UnaryServerInterceptor()))
+	svc := &recordingServer{}
+	bookiePb.RegisterBookieServer(server, svc)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := bookiePb.NewBookieClient(conn)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		verified bool // the caller presented a verified client certificate
+		want     Principal
+		wantSeen bool
+	}{
+		{
+			name:     "forwards principal",
+			ctx:      NewContext(context.Background(), Principal{Subject: "ci", Method: MethodAPIKey}),
+			verified: true,
+			want:     Principal{Subject: "ci", Method: MethodAPIKey},
+			wantSeen: true,
+		},
+		{name: "anonymous", ctx: context.Background(), verified: true},
+		{
+			name:     "ignores unknown method",
+			ctx:      metadata.AppendToOutgoingContext(context.Background(), SubjectMetadataKey, "ci", MethodMetadataKey, "password"),
+			verified: true,
+		},
+		{
+			name: "ignores principal without client certificate",
+			ctx:  NewContext(context.Background(), Principal{Subject: "admin", Method: MethodJWT}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			verified.Store(tt.verified)
+			if _, err := client.GetByID(tt.ctx, &bookiePb.GetByIDRequest{Id: "1"}); err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if svc.ok != tt.wantSeen || svc.principal != tt.want {
+				t.Errorf("server saw %+v (%v), want %+v (%v)", svc.principal, svc.ok, tt.want, tt.wantSeen)
+			}
+
+			var entry struct {
+				Principal string `json:"principal"`
+			}
+			if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+				t.Fatalf("decode log %q: %v", logs.String(), err)
+			}
+			if want := Attr(tt.want).Value.String(); tt.wantSeen && entry.Principal != want {
+				t.Errorf("logged principal %q, want %q", entry.Principal, want)
+			}
+			if !tt.wantSeen && entry.Principal != "" {
+				t.Errorf("logged principal %q for an anonymous call", entry.Principal)
+			}
+		})
+	}
+}
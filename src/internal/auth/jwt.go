@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// clockSkew is how far the clocks of token issuers may drift from ours.
+	clockSkew = 30 * time.Second
+	// minRSABits is the smallest RSA modulus accepted in a JWKS.
+	minRSABits = 2048
+)
+
+// signingMethods are the algorithms tokens may be signed with. Only public
+// key algorithms are listed, so a JWKS key can never be used as an HMAC
+// secret.
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// verificationKey is a public key of a JWKS with the algorithms it verifies.
+type verificationKey struct {
+	id     string
+	public any
+	algs   []string
+}
+
+// TokenVerifier verifies JWT bearer tokens against the public keys of a local
+// JWKS file.
+type TokenVerifier struct {
+	keys     []verificationKey
+	issuer   string
+	audience string
+}
+
+// NewTokenVerifier loads the JSON Web Key Set in jwksFile. Tokens must be
+// signed by one of its keys, carry an expiry and a subject, and, when issuer
+// or audience are not empty, name them in their "iss" and "aud" claims.
+func NewTokenVerifier(jwksFile, issuer, audience string) (*TokenVerifier, error) {
+	data, err := os.ReadFile(jwksFile)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", jwksFile, err)
+	}
+	return &TokenVerifier{keys: keys, issuer: issuer, audience: audience}, nil
+}
+
+// Verify checks the signature and claims of token and returns its principal.
+func (v *TokenVerifier) Verify(token string) (Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	var claims jwt.RegisteredClaims
+	if _, err := jwt.ParseWithClaims(token, &claims, v.key, opts...); err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	if !ValidSubject(claims.Subject) {
+		return Principal{}, fmt.Errorf("%w: missing or invalid subject", ErrInvalidCredentials)
+	}
+	return Principal{Subject: claims.Subject, Method: MethodJWT}, nil
+}
+
+// key returns the public key that verifies token: the one named by its "kid"
+// header, or the only key of the set when the header is absent.
+func (v *TokenVerifier) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	var key *verificationKey
+	switch {
+	case kid != "":
+		for i := range v.keys {
+			if v.keys[i].id == kid {
+				key = &v.keys[i]
+				break
+			}
+		}
+	case len(v.keys) == 1:
+		key = &v.keys[0]
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no key for kid %q", kid)
+	}
+	if alg := token.Method.Alg(); !slices.Contains(key.algs, alg) {
+		return nil, fmt.Errorf("key %q does not verify %s", key.id, alg)
+	}
+	return key.public, nil
+}
+
+// jsonWebKey holds the members of a JWK (RFC 7517) used for verification.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the signature keys of a JWKS. Keys meant for encryption
+// are skipped; keys of unsupported types are an error.
+func parseJWKS(data []byte) ([]verificationKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	var keys []verificationKey
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.verificationKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %d (kid %q): %w", i, jwk.Kid, err)
+		}
+		if jwk.Alg != "" {
+			if !slices.Contains(key.algs, jwk.Alg) {
+				return nil, fmt.Errorf("key %d (kid %q): alg %q does not suit a %s key", i, jwk.Kid, jwk.Alg, jwk.Kty)
+			}
+			key.algs = []string{jwk.Alg}
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no signature keys")
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) verificationKey() (verificationKey, error) {
+	key := verificationKey{id: jwk.Kid}
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return key, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return key, errors.New("invalid exponent")
+		}
+		if n.BitLen() < minRSABits {
+			return key, fmt.Errorf("RSA modulus shorter than %d bits", minRSABits)
+		}
+		key.public = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		key.algs = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+	case "EC":
+		curves := map[string]struct {
+			curve elliptic.Curve
+			alg   string
+		}{
+			"P-256": {elliptic.P256(), "ES256"},
+			"P-384": {elliptic.P384(), "ES384"},
+			"P-521": {elliptic.P521(), "ES512"},
+		}
+		c, ok := curves[jwk.Crv]
+		if !ok {
+			return key, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		size := (c.curve.Params().BitSize + 7) / 8
+		x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+		y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return key, errors.New("invalid coordinates")
+		}
+		public, err := ecdsa.ParseUncompressedPublicKey(c.curve, append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return key, err
+		}
+		key.public = public
+		key.algs = []string{c.alg}
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if jwk.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return key, errors.New("only Ed25519 OKP keys are supported")
+		}
+		key.public = ed25519.PublicKey(x)
+		key.algs = []string{"EdDSA"}
+	default:
+		return key, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+	return key, nil
+}
+
+// decodeInt decodes a base64url big-endian unsigned integer.
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signer holds the private keys of a test JWKS.
+type signer struct {
+	ec   *ecdsa.PrivateKey
+	rsa  *rsa.PrivateKey
+	ed   ed25519.PrivateKey
+	jwks []byte
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newSigner(t *testing.T) *signer {
+	t.Helper()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate Ed25519 key: %v", err)
+	}
+	ecPoint, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("encode EC key: %v", err)
+	}
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "kid": "ec", "use": "sig", "crv": "P-256", "x": b64(ecPoint[1:33]), "y": b64(ecPoint[33:])},
+		{"kty": "RSA", "kid": "rsa", "alg": "RS256", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPublic)},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	if err != nil {
+		t.Fatalf("marshal JWKS: %v", err)
+	}
+	return &signer{ec: ecKey, rsa: rsaKey, ed: edKey, jwks: jwks}
+}
+
+func (s *signer) verifier(t *testing.T, issuer, audience string) *TokenVerifier {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, s.jwks, 0o600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+	v, err := NewTokenVerifier(path, issuer, audience)
+	if err != nil {
+		t.Fatalf("NewTokenVerifier: %v", err)
+	}
+	return v
+}
+
+// sign signs claims with the EC key.
+func (s *signer) sign(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	return s.signWith(t, jwt.SigningMethodES256, "ec", s.ec, claims)
+}
+
+func (s *signer) signWith(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "user-1",
+		Issuer:    "https://id.example.com",
+		Audience:  jwt.ClaimStrings{"bookie"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func TestVerify(t *testing.T) {
+	s, other := newSigner(t), newSigner(t)
+	verifier := s.verifier(t, "https://id.example.com", "bookie")
+
+	with := func(change func(*jwt.RegisteredClaims)) jwt.RegisteredClaims {
+		claims := validClaims()
+		change(&claims)
+		return claims
+	}
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "ES256", token: s.sign(t, validClaims())},
+		{name: "RS256", token: s.signWith(t, jwt.SigningMethodRS256, "rsa", s.rsa, validClaims())},
+		{name: "EdDSA", token: s.signWith(t, jwt.SigningMethodEdDSA, "ed", s.ed, validClaims())},
+		{name: "within clock skew", token: s.sign(t, with(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second))
+		}))},
+		{name: "expired", token: s.sign(t, with(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		})), wantErr: true},
+		{name: "no expiry", token: s.sign(t, with(func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil })), wantErr: true},
+		{name: "not yet valid", token: s.sign(t, with(func(c *jwt.RegisteredClaims) {
+			c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		})), wantErr: true},
+		{name: "wrong issuer", token: s.sign(t, with(func(c *jwt.RegisteredClaims) { c.Issuer = "https://evil.example.com" })), wantErr: true},
+		{name: "wrong audience", token: s.sign(t, with(func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"other"} })), wantErr: true},
+		{name: "no subject", token: s.sign(t, with(func(c *jwt.RegisteredClaims) { c.Subject = "" })), wantErr: true},
+		{name: "subject not forwardable", token: s.sign(t, with(func(c *jwt.RegisteredClaims) { c.Subject = "new\nline" })), wantErr: true},
+		{name: "signed by another key", token: other.sign(t, validClaims()), wantErr: true},
+		{name: "unknown kid", token: s.signWith(t, jwt.SigningMethodES256, "missing", s.ec, validClaims()), wantErr: true},
+		{name: "no kid with several keys", token: s.signWith(t, jwt.SigningMethodES256, "", s.ec, validClaims()), wantErr: true},
+		{name: "algorithm the key does not allow", token: s.signWith(t, jwt.SigningMethodPS256, "rsa", s.rsa, validClaims()), wantErr: true},
+		{name: "key of another type", token: s.signWith(t, jwt.SigningMethodRS256, "ec", s.rsa, validClaims()), wantErr: true},
+		{name: "HMAC with the public key", token: s.signWith(t, jwt.SigningMethodHS256, "ed", []byte(s.ed.Public().(ed25519.PublicKey)), validClaims()), wantErr: true},
+		{name: "unsigned", token: s.signWith(t, jwt.SigningMethodNone, "ec", jwt.UnsafeAllowNoneSignatureType, validClaims()), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := verifier.Verify(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("Verify error = %v, want ErrInvalidCredentials", err)
+			}
+			if err == nil && p != (Principal{Subject: "user-1", Method: MethodJWT}) {
+				t.Errorf("Verify = %+v", p)
+			}
+		})
+	}
+}
+
+func TestVerifySingleKeyWithoutKid(t *testing.T) {
+	s := newSigner(t)
+	ecPoint, _ := s.ec.PublicKey.Bytes()
+	s.jwks, _ = json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "crv": "P-256", "x": b64(ecPoint[1:33]), "y": b64(ecPoint[33:])},
+	}})
+	if _, err := s.verifier(t, "", "").Verify(s.signWith(t, jwt.SigningMethodES256, "", s.ec, validClaims())); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	tests := []struct {
+		name string
+		jwks string
+	}{
+		{name: "not JSON", jwks: "keys"},
+		{name: "no keys", jwks: `{"keys": []}`},
+		{name: "only encryption keys", jwks: `{"keys": [{"kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`},
+		{name: "symmetric key", jwks: `{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`},
+		{name: "short RSA modulus", jwks: `{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAB"}]}`},
+		{name: "unknown curve", jwks: `{"keys": [{"kty": "EC", "crv": "P-192", "x": "AQAB", "y": "AQAB"}]}`},
+		{name: "point off the curve", jwks: `{"keys": [{"kty": "EC", "crv": "P-256", "x": "` + b64(make([]byte, 32)) + `", "y": "` + b64(make([]byte, 32)) + `"}]}`},
+		{name: "alg of another key type", jwks: `{"keys": [{"kty": "OKP", "crv": "Ed25519", "alg": "RS256", "x": "` + b64(make([]byte, 32)) + `"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseJWKS([]byte(tt.jwks)); err == nil {
+				t.Errorf("parseJWKS(%s) succeeded, want error", tt.jwks)
+			}
+		})
+	}
+}
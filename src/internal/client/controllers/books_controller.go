@@ -2,42 +2,308 @@
 package controllers
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/sadhakbj/bookie-grpc/src/internal/services/books"
 	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
 )
 
+const (
+	// maxBookBodyBytes limits the size of a request body holding a single book.
+	maxBookBodyBytes = 64 << 10
+	// maxBatchBodyBytes limits the size of a batch create request body.
+	maxBatchBodyBytes = 10 << 20
+)
+
 // BookController handles HTTP requests related to book operations.
 type BookController struct {
-	bookClient *books.GRPCClient
+	bookClient books.BookService
+	// shutdown is closed by Shutdown to end the open event streams.
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
 }
 
-// NewBookController creates a new BookController with the given gRPC client.
-func NewBookController(bookClient *books.GRPCClient) *BookController {
+// NewBookController creates a new BookController backed by the given book service.
+func NewBookController(bookClient books.BookService) *BookController {
 	return &BookController{
 		bookClient: bookClient,
+		shutdown:   make(chan struct{}),
 	}
 }
 
+// Shutdown ends the open event streams, which would otherwise keep
+// http.Server.Shutdown waiting. Other requests are left to finish. Register
+// it with http.Server.RegisterOnShutdown.
+func (bc *BookController) Shutdown() {
+	bc.shutdownOnce.Do(func() { close(bc.shutdown) })
+}
+
 // FetchBookByID handles HTTP GET requests to fetch a book by its ID.
 func (bc *BookController) FetchBookByID(w http.ResponseWriter, req *http.Request) {
-	book, err := bc.bookClient.GetByID(req.PathValue("id"))
+	book, err := bc.bookClient.GetByID(req.Context(), req.PathValue("id"))
 	if err != nil {
-		utils.HandleGRPCError(w, err)
+		utils.HandleGRPCError(w, req, err)
 		return
 	}
 
 	utils.JSONResponse(w, http.StatusOK, true, "Fetched data successfully", []interface{}{book})
 }
 
-// FetchAllBooks handles HTTP GET requests to fetch all books.
-func (bc *BookController) FetchAllBooks(w http.ResponseWriter, _ *http.Request) {
-	books, err := bc.bookClient.GetBooks()
+// FetchAllBooks handles HTTP GET requests to fetch a page of books.
+// Supported query parameters are page_size, page_token, filter, order_by and show_deleted.
+func (bc *BookController) FetchAllBooks(w http.ResponseWriter, req *http.Request) {
+	showDeleted, err := parseBoolQuery(req, "show_deleted")
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, false, "Invalid show_deleted value", nil)
+		return
+	}
+	pageSize, err := parsePageSize(req)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, false, "Invalid page_size value", nil)
+		return
+	}
+
+	page, err := bc.bookClient.GetBooks(req.Context(), books.ListOptions{
+		ShowDeleted: showDeleted,
+		PageSize:    pageSize,
+		PageToken:   req.URL.Query().Get("page_token"),
+		Filter:      req.URL.Query().Get("filter"),
+		OrderBy:     req.URL.Query().Get("order_by"),
+	})
+	if err != nil {
+		utils.HandleGRPCError(w, req, err)
+		return
+	}
+
+	utils.JSONPageResponse(w, "Successfully fetched books", page.Books, utils.Pagination{
+		NextPageToken: page.NextPageToken,
+		TotalSize:     page.TotalSize,
+		Revision:      page.Revision,
+	})
+}
+
+// CreateBook handles HTTP POST requests that create a single book. The new
+// book's URL is returned in the Location header.
+func (bc *BookController) CreateBook(w http.ResponseWriter, req *http.Request) {
+	var input books.BookInput
+	if !decodeJSONBody(w, req, maxBookBodyBytes, &input) {
+		return
+	}
+
+	book, err := bc.bookClient.CreateBook(req.Context(), &input)
+	if err != nil {
+		utils.HandleGRPCError(w, req, err)
+		return
+	}
+
+	w.Header().Set("Location", "/books/"+url.PathEscape(book.ID))
+	utils.JSONResponse(w, http.StatusCreated, true, "Created book successfully", []interface{}{book})
+}
+
+// UpdateBook handles HTTP PATCH requests that change some fields of a book.
+func (bc *BookController) UpdateBook(w http.ResponseWriter, req *http.Request) {
+	var update books.BookUpdate
+	if !decodeJSONBody(w, req, maxBookBodyBytes, &update) {
+		return
+	}
+	if update.IsEmpty() {
+		utils.JSONResponse(w, http.StatusBadRequest, false, "Nothing to update", nil)
+		return
+	}
+
+	book, err := bc.bookClient.UpdateBook(req.Context(), req.PathValue("id"), &update)
+	if err != nil {
+		utils.HandleGRPCError(w, req, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, true, "Updated book successfully", []interface{}{book})
+}
+
+// DeleteBook handles HTTP DELETE requests that soft-delete a book.
+func (bc *BookController) DeleteBook(w http.ResponseWriter, req *http.Request) {
+	book, err := bc.bookClient.DeleteBook(req.Context(), req.PathValue("id"))
+	if err != nil {
+		utils.HandleGRPCError(w, req, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, true, "Deleted book successfully", []interface{}{book})
+}
+
+// UndeleteBook handles HTTP POST requests to /books/{id}:undelete that restore a soft-deleted book.
+// ServeMux wildcards must span a whole path segment, so the route is registered
+// as /books/{name} and the :undelete suffix is checked here.
+func (bc *BookController) UndeleteBook(w http.ResponseWriter, req *http.Request) {
+	id, ok := strings.CutSuffix(req.PathValue("name"), ":undelete")
+	if !ok || id == "" {
+		utils.JSONResponse(w, http.StatusNotFound, false, "Item not found", nil)
+		return
+	}
+
+	book, err := bc.bookClient.UndeleteBook(req.Context(), id)
+	if err != nil {
+		utils.HandleGRPCError(w, req, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, true, "Restored book successfully", []interface{}{book})
+}
+
+// decodeJSONBody decodes a request body holding exactly one JSON value into v,
+// rejecting unknown fields and bodies over maxBytes. On failure it writes the
+// error response and returns false.
+func decodeJSONBody(w http.ResponseWriter, req *http.Request, maxBytes int64, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBytes))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err == nil {
+		// Anything but EOF after the value means trailing data.
+		if err = decoder.Decode(&struct{}{}); errors.Is(err, io.EOF) {
+			return true
+		}
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.JSONResponse(w, http.StatusRequestEntityTooLarge, false, "Request body too large", nil)
+		return false
+	}
+	utils.JSONResponse(w, http.StatusBadRequest, false, "Invalid request body", nil)
+	return false
+}
+
+// parseBoolQuery reads an optional boolean query parameter, defaulting to false.
+func parseBoolQuery(req *http.Request, key string) (bool, error) {
+	value := req.URL.Query().Get(key)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parsePageSize reads the optional page_size query parameter. Zero means the server default.
+func parsePageSize(req *http.Request) (int, error) {
+	value := req.URL.Query().Get("page_size")
+	if value == "" {
+		return 0, nil
+	}
+	size, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if size < 0 {
+		return 0, errors.New("page_size must not be negative")
+	}
+	return int(size), nil
+}
+
+// ExportBooks handles HTTP GET requests that stream every matching book as
+// newline-delimited JSON. Books are written as they arrive from the gRPC
+// stream instead of being buffered. Supports filter, order_by and show_deleted.
+func (bc *BookController) ExportBooks(w http.ResponseWriter, req *http.Request) {
+	showDeleted, err := parseBoolQuery(req, "show_deleted")
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, false, "Invalid show_deleted value", nil)
+		return
+	}
+
+	bookStream := bc.bookClient.StreamBooks(req.Context(), books.StreamOptions{
+		ShowDeleted: showDeleted,
+		Filter:      req.URL.Query().Get("filter"),
+		OrderBy:     req.URL.Query().Get("order_by"),
+	})
+
+	rc := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	started := false
+	for book, err := range bookStream {
+		if err != nil {
+			if !started {
+				utils.HandleGRPCError(w, req, err)
+				return
+			}
+			// The status line is already sent; cut the stream short so the
+			// client sees an incomplete body rather than a silent success.
+			utils.LoggerFromContext(req.Context()).Error("Error while exporting books", "error", err)
+			panic(http.ErrAbortHandler)
+		}
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := encoder.Encode(book); err != nil {
+			return
+		}
+		_ = rc.Flush()
+	}
+
+	if !started {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// batchCreateRequest is the body of POST /books:batchCreate.
+type batchCreateRequest struct {
+	Books []*books.BookInput `json:"books"`
+}
+
+// BatchCreateBooks handles HTTP POST requests that create several books at once.
+// Either every book is created or none is.
+func (bc *BookController) BatchCreateBooks(w http.ResponseWriter, req *http.Request) {
+	var body batchCreateRequest
+	if !decodeJSONBody(w, req, maxBatchBodyBytes, &body) {
+		return
+	}
+	if len(body.Books) == 0 {
+		utils.JSONResponse(w, http.StatusBadRequest, false, "No books to create", nil)
+		return
+	}
+	if slices.Contains(body.Books, nil) {
+		utils.JSONResponse(w, http.StatusBadRequest, false, "Invalid request body", nil)
+		return
+	}
+
+	created, err := bc.bookClient.BatchCreateBooks(req.Context(), body.Books)
+	if err != nil {
+		utils.HandleGRPCError(w, req, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, true, "Created books successfully", created)
+}
+
+// BatchGetBooks handles HTTP GET requests that fetch several books by id.
+// Ids are passed as repeated or comma-separated ids query parameters.
+func (bc *BookController) BatchGetBooks(w http.ResponseWriter, req *http.Request) {
+	var ids []string
+	for _, value := range req.URL.Query()["ids"] {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		utils.JSONResponse(w, http.StatusBadRequest, false, "Please provide ids", nil)
+		return
+	}
+
+	result, err := bc.bookClient.BatchGetBooks(req.Context(), ids)
 	if err != nil {
-		utils.HandleGRPCError(w, err)
+		utils.HandleGRPCError(w, req, err)
 		return
 	}
 
-	utils.JSONResponse(w, http.StatusOK, true, "Successfully fetched books", books)
+	utils.JSONResponse(w, http.StatusOK, true, "Fetched data successfully", result)
 }
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/services/books"
+	"github.com/sadhakbj/bookie-grpc/src/internal/services/books/bookstest"
+)
+
+// errTransport is what the gRPC client returns when the server is unreachable.
+var errTransport = status.Error(codes.Unavailable, "connection error: dial tcp 127.0.0.1:8020: connect: connection refused")
+
+// newFake returns a fake holding two live books, "1" and "2", and the
+// soft-deleted book "3".
+func newFake() *bookstest.Fake {
+	deleted := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return bookstest.NewFake(
+		&books.Book{ID: "1", Title: "Harry Potter", Author: "JK Rowling", Price: 120},
+		&books.Book{ID: "2", Title: "Game of life", Author: "Author Two", Price: 450},
+		&books.Book{ID: "3", Title: "Lost book", Author: "Nobody", Price: 10, DeleteTime: &deleted},
+	)
+}
+
+// newMux returns the routes of a controller backed by fake, without deadlines.
+func newMux(t *testing.T, fake *bookstest.Fake) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+	if err := NewBookController(fake).RegisterRoutes(mux, 0, nil); err != nil {
+		t.Fatalf("RegisterRoutes: %v", err)
+	}
+	return mux
+}
+
+// serve sends req through the routes of a controller backed by fake.
+func serve(t *testing.T, fake *bookstest.Fake, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	newMux(t, fake).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRoutes(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		failing     bool // the fake fails every call like an unreachable server
+		wantStatus  int
+		wantBody    []string // substrings of the response body
+	}{
+		{name: "get book", method: "GET", target: "/books/1", wantStatus: 200, wantBody: []string{`"title":"Harry Potter"`}},
+		{name: "get missing book", method: "GET", target: "/books/missing", wantStatus: 404, wantBody: []string{"Book with ID missing not found"}},
+		{name: "get deleted book", method: "GET", target: "/books/3", wantStatus: 404},
+		{name: "get book unavailable", method: "GET", target: "/books/1", failing: true, wantStatus: 503},
+
+		{name: "list books", method: "GET", target: "/books?page_size=1", wantStatus: 200, wantBody: []string{`"id":"1"`, `"next_page_token":"1"`, `"total_size":2`, `"revision":0`}},
+		{name: "list deleted books", method: "GET", target: "/books?show_deleted=true", wantStatus: 200, wantBody: []string{`"total_size":3`}},
+		{name: "list books bad page size", method: "GET", target: "/books?page_size=ten", wantStatus: 400},
+		{name: "list books unavailable", method: "GET", target: "/books", failing: true, wantStatus: 503},
+
+		{name: "create book", method: "POST", target: "/books", body: `{"title": "Dune"}`, wantStatus: 201, wantBody: []string{`"id":"fake-1"`}},
+		{name: "create existing book", method: "POST", target: "/books", body: `{"id": "1", "title": "Dune"}`, wantStatus: 409},
+		{name: "create book unavailable", method: "POST", target: "/books", body: `{"title": "Dune"}`, failing: true, wantStatus: 503},
+
+		{name: "update book", method: "PATCH", target: "/books/1", body: `{"price": 150}`, wantStatus: 200, wantBody: []string{`"price":150`, `"title":"Harry Potter"`}},
+		{name: "update missing book", method: "PATCH", target: "/books/missing", body: `{"price": 150}`, wantStatus: 404},
+		{name: "update nothing", method: "PATCH", target: "/books/1", body: `{}`, wantStatus: 400, wantBody: []string{"Nothing to update"}},
+		{name: "update book unavailable", method: "PATCH", target: "/books/1", body: `{"price": 150}`, failing: true, wantStatus: 503},
+
+		{name: "delete book", method: "DELETE", target: "/books/1", wantStatus: 200, wantBody: []string{`"delete_time"`}},
+		{name: "delete missing book", method: "DELETE", target: "/books/missing", wantStatus: 404},
+		{name: "delete book unavailable", method: "DELETE", target: "/books/1", failing: true, wantStatus: 503},
+
+		{name: "undelete book", method: "POST", target: "/books/3:undelete", wantStatus: 200, wantBody: []string{`"title":"Lost book"`}},
+		{name: "undelete missing book", method: "POST", target: "/books/missing:undelete", wantStatus: 404},
+		{name: "undelete live book", method: "POST", target: "/books/1:undelete", wantStatus: 400},
+		{name: "undelete without verb", method: "POST", target: "/books/3", wantStatus: 404},
+		{name: "undelete book unavailable", method: "POST", target: "/books/3:undelete", failing: true, wantStatus: 503},
+
+		{name: "export books", method: "GET", target: "/books/export", wantStatus: 200, wantBody: []string{`{"id":"1"`, `{"id":"2"`}},
+		{name: "export books unavailable", method: "GET", target: "/books/export", failing: true, wantStatus: 503},
+
+		{name: "batch create", method: "POST", target: "/books:batchCreate", body: `{"books": [{"title": "A"}, {"title": "B"}]}`, wantStatus: 201, wantBody: []string{`"id":"fake-1"`, `"id":"fake-2"`}},
+		{name: "batch create existing", method: "POST", target: "/books:batchCreate", body: `{"books": [{"id": "1", "title": "A"}]}`, wantStatus: 409},
+		{name: "batch create unavailable", method: "POST", target: "/books:batchCreate", body: `{"books": [{"title": "A"}]}`, failing: true, wantStatus: 503},
+
+		{name: "batch get", method: "GET", target: "/books:batchGet?ids=1,missing", wantStatus: 200, wantBody: []string{`"id":"1"`, `"missing_ids":["missing"]`}},
+		{name: "batch get without ids", method: "GET", target: "/books:batchGet", wantStatus: 400},
+		{name: "batch get unavailable", method: "GET", target: "/books:batchGet?ids=1", failing: true, wantStatus: 503},
+
+		{name: "import csv", method: "POST", target: "/books/import", contentType: "text/csv", body: "id,title\n1,Dup\n,New\n,\n", wantStatus: 200, wantBody: []string{`"created":1`, `"skipped":1`, `"failed":1`, `"reason":"title is required"`}},
+		{name: "import unsupported format", method: "POST", target: "/books/import", contentType: "application/pdf", body: "%PDF", wantStatus: 400},
+		{name: "import unavailable", method: "POST", target: "/books/import", contentType: "text/csv", body: "title\nNew\n", failing: true, wantStatus: 503},
+
+		{name: "events unavailable", method: "GET", target: "/books/events", failing: true, wantStatus: 200, wantBody: []string{"event: error", `"code":"Unavailable"`}},
+		{name: "events bad last event id", method: "GET", target: "/books/events?last_event_id=x", wantStatus: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFake()
+			if tt.failing {
+				fake.Err = errTransport
+			}
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			rec := serve(t, fake, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body does not contain %s: %s", want, rec.Body)
+				}
+			}
+			if tt.failing && strings.Contains(rec.Body.String(), "127.0.0.1:8020") {
+				t.Errorf("body leaks the upstream address: %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestCreateBook(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantLocation string
+		wantID       string
+		wantErrors   int
+	}{
+		{
+			name:         "creates book with generated id",
+			body:         `{"title": "Dune", "author": "Frank Herbert", "price": 120}`,
+			wantStatus:   http.StatusCreated,
+			wantLocation: "/books/fake-1",
+			wantID:       "fake-1",
+		},
+		{
+			name:         "keeps client id",
+			body:         `{"id": "dune~1965", "title": "Dune"}`,
+			wantStatus:   http.StatusCreated,
+			wantLocation: "/books/dune~1965",
+			wantID:       "dune~1965",
+		},
+		{
+			name:       "rejects unknown fields",
+			body:       `{"title": "Dune", "isbn": "9780441013593"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects malformed json",
+			body:       `{"title": "Dune"`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects empty body",
+			body:       ``,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects trailing data",
+			body:       `{"title": "Dune"} {"title": "Emma"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects wrong field type",
+			body:       `{"title": "Dune", "price": "cheap"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects oversized body",
+			body:       `{"title": "Dune", "description": "` + strings.Repeat("x", maxBookBodyBytes) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "surfaces validation errors",
+			body:       `{"title": "", "price": -1}`,
+			wantStatus: http.StatusBadRequest,
+			wantErrors: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := bookstest.NewFake()
+			req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(tt.body))
+			rec := serve(t, fake, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+			if _, stored := fake.Book(tt.wantID); tt.wantID != "" && !stored {
+				t.Errorf("book %q was not stored", tt.wantID)
+			}
+
+			var body struct {
+				Success bool              `json:"success"`
+				Data    []*books.Book     `json:"data"`
+				Errors  []json.RawMessage `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Success != (tt.wantStatus == http.StatusCreated) {
+				t.Errorf("success = %v for status %d", body.Success, rec.Code)
+			}
+			if body.Success && (len(body.Data) != 1 || body.Data[0].ID != tt.wantID) {
+				t.Errorf("data = %+v, want the created book", body.Data)
+			}
+			if len(body.Errors) != tt.wantErrors {
+				t.Errorf("errors = %s, want %d entries", body.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/services/books"
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// sseHeartbeatInterval is how often an idle event stream sends a comment line
+// so proxies and browsers do not time the connection out.
+const sseHeartbeatInterval = 15 * time.Second
+
+// watchResult carries one item of the WatchBooks iterator across goroutines.
+type watchResult struct {
+	event *books.BookEvent
+	err   error
+}
+
+// StreamBookEvents handles HTTP GET requests that subscribe to live catalog
+// changes as Server-Sent Events. Each event id is the change revision, so a
+// reconnecting EventSource resumes via the Last-Event-ID header. The stream
+// ends when the client disconnects or Shutdown is called.
+func (bc *BookController) StreamBookEvents(w http.ResponseWriter, req *http.Request) {
+	afterRevision, err := lastEventID(req)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, false, "Invalid Last-Event-ID", nil)
+		return
+	}
+
+	ctx := req.Context()
+	results := make(chan watchResult)
+	go func() {
+		defer close(results)
+		for event, err := range bc.bookClient.WatchBooks(ctx, afterRevision) {
+			select {
+			case results <- watchResult{event: event, err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-bc.shutdown:
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case result, ok := <-results:
+			if !ok {
+				return
+			}
+			if result.err != nil {
+				writeSSEError(ctx, w, result.err)
+				_ = rc.Flush()
+				return
+			}
+			if err := writeSSEEvent(w, result.event); err != nil {
+				utils.LoggerFromContext(ctx).Error("Error while writing book event", "error", err)
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// lastEventID returns the revision to resume after from the Last-Event-ID
+// header, falling back to the last_event_id query parameter for clients that
+// cannot set headers. Zero means start with new events.
+func lastEventID(req *http.Request) (int64, error) {
+	value := req.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = req.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || revision < 0 {
+		return 0, fmt.Errorf("invalid event id %q", value)
+	}
+	return revision, nil
+}
+
+func writeSSEEvent(w http.ResponseWriter, event *books.BookEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Revision, event.Type, data)
+	return err
+}
+
+// writeSSEError reports why the upstream watch ended as an "error" event.
+// Clients that fell behind can reconnect and resume from their last event id.
+// The event carries the same sanitized message as HTTP error responses; the
+// full error is logged.
+func writeSSEError(ctx context.Context, w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	if st.Code() == codes.Canceled {
+		return
+	}
+	utils.LoggerFromContext(ctx).Warn("Book watch ended", "error", err)
+	_, message := utils.GrpcErrorToHTTPStatus(err)
+	payload := map[string]interface{}{
+		"code":    st.Code().String(),
+		"message": message,
+	}
+	if details := utils.GRPCErrorDetails(st); len(details) > 0 {
+		payload["errors"] = details
+	}
+	data, _ := json.Marshal(payload)
+	_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/services/books"
+)
+
+// TestStreamBookEvents reads live events over a real connection, since the
+// handler only returns once the client goes away.
+func TestStreamBookEvents(t *testing.T) {
+	fake := newFake()
+	server := httptest.NewServer(newMux(t, fake))
+	defer server.Close()
+
+	if _, err := fake.CreateBook(context.Background(), &books.BookInput{Title: "Dune"}); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if _, err := fake.DeleteBook(context.Background(), "1"); err != nil {
+		t.Fatalf("DeleteBook: %v", err)
+	}
+
+	tests := []struct {
+		lastEventID string
+		want        []string
+	}{
+		// Without Last-Event-ID no history is replayed: the first event is
+		// one created after connecting, not the creation of Dune.
+		{"", []string{"event: created", `"title":"Live"`}},
+		{"1", []string{"id: 2", "event: deleted", `"id":"1"`}},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/books/events", nil)
+		if tt.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", tt.lastEventID)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			cancel()
+			t.Fatalf("GET /books/events: %v", err)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+
+		// The watch may start after the headers are sent, so keep creating
+		// books until one is streamed.
+		var wg sync.WaitGroup
+		if tt.lastEventID == "" {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for ctx.Err() == nil {
+					_, _ = fake.CreateBook(ctx, &books.BookInput{Title: "Live"})
+					time.Sleep(10 * time.Millisecond)
+				}
+			}()
+		}
+
+		// The first event is complete once its data line has been read.
+		var event []string
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			event = append(event, scanner.Text())
+			if strings.HasPrefix(scanner.Text(), "data: ") {
+				break
+			}
+		}
+		cancel()
+		wg.Wait()
+		_ = res.Body.Close()
+
+		got := strings.Join(event, "\n")
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("Last-Event-ID %q: event %q does not contain %s", tt.lastEventID, got, want)
+			}
+		}
+	}
+}
+
+// TestShutdownDrainsRequests shuts the server down while an event stream and
+// a slow request are open, wired like the client binary. The stream must end
+// and the request must still complete.
+func TestShutdownDrainsRequests(t *testing.T) {
+	fake := newFake()
+	fake.Latency = 200 * time.Millisecond
+	controller := NewBookController(fake)
+	mux := http.NewServeMux()
+	if err := controller.RegisterRoutes(mux, 0, nil); err != nil {
+		t.Fatalf("RegisterRoutes: %v", err)
+	}
+	started := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/books/1" {
+			close(started)
+		}
+		mux.ServeHTTP(w, req)
+	})}
+	server.RegisterOnShutdown(controller.Shutdown)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.Serve(listener) }()
+	baseURL := "http://" + listener.Addr().String()
+
+	stream, err := http.Get(baseURL + "/books/events")
+	if err != nil {
+		t.Fatalf("GET /books/events: %v", err)
+	}
+	defer stream.Body.Close()
+
+	slow := make(chan *http.Response, 1)
+	go func() {
+		res, err := http.Get(baseURL + "/books/1")
+		if err != nil {
+			t.Errorf("GET /books/1: %v", err)
+		}
+		slow <- res
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if res := <-slow; res != nil {
+		_ = res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("GET /books/1 during shutdown: status %d, want 200", res.StatusCode)
+		}
+	}
+	if _, err := io.ReadAll(stream.Body); err != nil {
+		t.Errorf("event stream did not end cleanly: %v", err)
+	}
+}
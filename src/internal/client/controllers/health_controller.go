@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// readinessTimeout bounds the backend check of one readiness probe.
+const readinessTimeout = 2 * time.Second
+
+// HealthChecker reports whether the backend can serve requests.
+// books.GRPCClient implements it with the gRPC health service.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthController serves the liveness and readiness probes.
+type HealthController struct {
+	backend HealthChecker
+}
+
+// NewHealthController creates a HealthController whose readiness depends on backend.
+func NewHealthController(backend HealthChecker) *HealthController {
+	return &HealthController{backend: backend}
+}
+
+// RegisterRoutes adds /healthz and /readyz to mux.
+func (hc *HealthController) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", hc.Healthz)
+	mux.HandleFunc("GET /readyz", hc.Readyz)
+}
+
+// Healthz reports that the process is up. It checks nothing else, so a
+// failing backend never gets the BFF restarted.
+func (hc *HealthController) Healthz(w http.ResponseWriter, _ *http.Request) {
+	utils.JSONResponse(w, http.StatusOK, true, "OK", nil)
+}
+
+// Readyz reports whether requests can be served, which needs the gRPC server
+// to report the Bookie service as SERVING.
+func (hc *HealthController) Readyz(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := hc.backend.CheckHealth(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Warn("Readiness check failed", "error", err)
+		utils.JSONErrorResponse(w, http.StatusServiceUnavailable, "Book service is not ready", nil)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, true, "Ready", nil)
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		failing    bool
+		slow       bool
+		wantStatus int
+	}{
+		{name: "live", target: "/healthz", wantStatus: http.StatusOK},
+		{name: "live while backend is down", target: "/healthz", failing: true, wantStatus: http.StatusOK},
+		{name: "ready", target: "/readyz", wantStatus: http.StatusOK},
+		{name: "not ready while backend is down", target: "/readyz", failing: true, wantStatus: http.StatusServiceUnavailable},
+		{name: "not ready while backend hangs", target: "/readyz", slow: true, wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFake()
+			if tt.failing {
+				fake.Err = errTransport
+			}
+			if tt.slow {
+				fake.Latency = readinessTimeout + time.Second
+			}
+			mux := http.NewServeMux()
+			NewHealthController(fake).RegisterRoutes(mux)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/services/books"
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// maxImportLineBytes bounds a single NDJSON line of an import upload.
+const maxImportLineBytes = 1 << 20
+
+// Supported import formats.
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+// errUnsupportedFormat is returned for uploads that are neither CSV nor NDJSON.
+var errUnsupportedFormat = errors.New("unsupported import format, send text/csv or application/x-ndjson")
+
+// ImportBooks handles HTTP POST requests that import a catalog file. The body
+// is either a raw CSV / NDJSON upload or a multipart form with a "file" part.
+// Rows are parsed as they are read and streamed to the gRPC server, so the
+// file is never held in memory.
+func (bc *BookController) ImportBooks(w http.ResponseWriter, req *http.Request) {
+	body, format, err := importSource(req)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+
+	var rows iter.Seq2[books.ImportRow, error]
+	switch format {
+	case formatCSV:
+		rows, err = csvRows(body)
+		if err != nil {
+			utils.JSONResponse(w, http.StatusBadRequest, false, err.Error(), nil)
+			return
+		}
+	default:
+		rows = ndjsonRows(body)
+	}
+
+	summary, err := bc.bookClient.ImportBooks(req.Context(), rows)
+	var readErr *importReadError
+	if errors.As(err, &readErr) {
+		utils.JSONResponse(w, http.StatusBadRequest, false, readErr.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.HandleGRPCError(w, req, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, true, "Imported books", summary)
+}
+
+// importSource returns the upload stream and its format. For multipart
+// requests it advances to the "file" part without buffering earlier parts.
+func importSource(req *http.Request) (io.Reader, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		format, err := formatOf(mediaType, "")
+		return req.Body, format, err
+	}
+
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, "", errors.New(`multipart upload has no "file" part`)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		format, err := formatOf(partType, part.FileName())
+		return part, format, err
+	}
+}
+
+// formatOf picks the import format from a media type, falling back to the file extension.
+func formatOf(mediaType, fileName string) (string, error) {
+	switch mediaType {
+	case "text/csv":
+		return formatCSV, nil
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return formatNDJSON, nil
+	}
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".csv":
+		return formatCSV, nil
+	case ".ndjson", ".jsonl":
+		return formatNDJSON, nil
+	}
+	return "", errUnsupportedFormat
+}
+
+// importReadError wraps failures to read the upload itself, as opposed to
+// single malformed rows which are reported in the summary.
+type importReadError struct {
+	err error
+}
+
+func (e *importReadError) Error() string {
+	return "could not read upload: " + e.err.Error()
+}
+
+func (e *importReadError) Unwrap() error {
+	return e.err
+}
+
+// csvRows reads a CSV file with a header row naming the columns id, title,
+// description, author and price. Only title is required. Rows are numbered by
+// their line in the file.
+func csvRows(r io.Reader) (iter.Seq2[books.ImportRow, error], error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch name {
+		case "id", "title", "description", "author", "price":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New(`CSV header must contain a "title" column`)
+	}
+
+	return func(yield func(books.ImportRow, error) bool) {
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				if !yield(books.ImportRow{Row: parseErr.StartLine, Err: parseErr.Err}, nil) {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				yield(books.ImportRow{}, &importReadError{err: err})
+				return
+			}
+
+			line, _ := reader.FieldPos(0)
+			if !yield(csvRecordToRow(line, record, columns), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func csvRecordToRow(line int, record []string, columns map[string]int) books.ImportRow {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	book := &books.BookInput{
+		ID:          field("id"),
+		Title:       field("title"),
+		Description: field("description"),
+		Author:      field("author"),
+	}
+	if price := field("price"); price != "" {
+		n, err := strconv.Atoi(price)
+		if err != nil {
+			return books.ImportRow{Row: line, Err: fmt.Errorf("invalid price %q", price)}
+		}
+		book.Price = n
+	}
+	return books.ImportRow{Row: line, Book: book}
+}
+
+// ndjsonRows reads one JSON book object per line. Blank lines are ignored and
+// rows are numbered by their line in the file.
+func ndjsonRows(r io.Reader) iter.Seq2[books.ImportRow, error] {
+	return func(yield func(books.ImportRow, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxImportLineBytes)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+
+			row := books.ImportRow{Row: line}
+			decoder := json.NewDecoder(strings.NewReader(text))
+			decoder.DisallowUnknownFields()
+			var book books.BookInput
+			err := decoder.Decode(&book)
+			if err == nil && decoder.InputOffset() < int64(len(text)) {
+				err = errors.New("unexpected data after the book object")
+			}
+			if err != nil {
+				row.Err = fmt.Errorf("invalid JSON: %w", err)
+			} else {
+				row.Book = &book
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(books.ImportRow{}, &importReadError{err: err})
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/services/books"
+)
+
+// wantRow is the expected outcome of one parsed import row: either a title
+// or a substring of the row error.
+type wantRow struct {
+	row   int
+	title string
+	err   string
+}
+
+// checkRows drains rows and compares them with want. It returns the error
+// that ended the iteration, if any.
+func checkRows(t *testing.T, rows iter.Seq2[books.ImportRow, error], want []wantRow) error {
+	t.Helper()
+	var got []books.ImportRow
+	var iterErr error
+	for row, err := range rows {
+		if err != nil {
+			iterErr = err
+			break
+		}
+		got = append(got, row)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		row := got[i]
+		if row.Row != w.row {
+			t.Errorf("row %d: number = %d, want %d", i, row.Row, w.row)
+		}
+		switch {
+		case w.err != "":
+			if row.Err == nil || !strings.Contains(row.Err.Error(), w.err) {
+				t.Errorf("row %d: error = %v, want one containing %q", w.row, row.Err, w.err)
+			}
+		case row.Err != nil:
+			t.Errorf("row %d: unexpected error %v", w.row, row.Err)
+		case row.Book.Title != w.title:
+			t.Errorf("row %d: title = %q, want %q", w.row, row.Book.Title, w.title)
+		}
+	}
+	return iterErr
+}
+
+func TestNDJSONRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []wantRow
+	}{
+		{
+			name:  "one book per line",
+			input: `{"title": "Dune", "price": 900}` + "\n" + `{"id": "emma", "title": "Emma"}` + "\n",
+			want:  []wantRow{{row: 1, title: "Dune"}, {row: 2, title: "Emma"}},
+		},
+		{
+			name:  "blank lines keep their number",
+			input: "\n  \n" + `{"title": "Dune"}` + "\r\n",
+			want:  []wantRow{{row: 3, title: "Dune"}},
+		},
+		{
+			name:  "missing final newline",
+			input: `{"title": "Dune"}`,
+			want:  []wantRow{{row: 1, title: "Dune"}},
+		},
+		{
+			name:  "trailing garbage",
+			input: `{"title": "a"} garbage`,
+			want:  []wantRow{{row: 1, err: "unexpected data after the book object"}},
+		},
+		{
+			name:  "two objects on a line",
+			input: `{"title": "a"} {"title": "b"}`,
+			want:  []wantRow{{row: 1, err: "unexpected data after the book object"}},
+		},
+		{
+			name:  "malformed line does not stop the import",
+			input: `{"title": "Dune"` + "\n" + `{"title": "Emma"}`,
+			want:  []wantRow{{row: 1, err: "invalid JSON"}, {row: 2, title: "Emma"}},
+		},
+		{
+			name:  "unknown field",
+			input: `{"title": "Dune", "isbn": "9780441013593"}`,
+			want:  []wantRow{{row: 1, err: "unknown field"}},
+		},
+		{
+			name:  "wrong field type",
+			input: `{"title": "Dune", "price": "cheap"}`,
+			want:  []wantRow{{row: 1, err: "invalid JSON"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkRows(t, ndjsonRows(strings.NewReader(tt.input)), tt.want); err != nil {
+				t.Errorf("iteration ended with %v", err)
+			}
+		})
+	}
+}
+
+func TestNDJSONRowsLineTooLong(t *testing.T) {
+	input := `{"title": "Dune"}` + "\n" + `{"title": "` + strings.Repeat("x", maxImportLineBytes) + `"}`
+	err := checkRows(t, ndjsonRows(strings.NewReader(input)), []wantRow{{row: 1, title: "Dune"}})
+	var readErr *importReadError
+	if !errors.As(err, &readErr) {
+		t.Errorf("iteration ended with %v, want an importReadError", err)
+	}
+}
+
+func TestCSVRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []wantRow
+	}{
+		{
+			name:  "header in any order and case",
+			input: "Price, TITLE ,id\n900,Dune,dune\n300,Emma,\n",
+			want:  []wantRow{{row: 2, title: "Dune"}, {row: 3, title: "Emma"}},
+		},
+		{
+			name:  "byte order mark",
+			input: "\ufefftitle,author\nDune,Frank Herbert\n",
+			want:  []wantRow{{row: 2, title: "Dune"}},
+		},
+		{
+			name:  "short rows leave columns empty",
+			input: "title,author,price\nDune\n",
+			want:  []wantRow{{row: 2, title: "Dune"}},
+		},
+		{
+			name:  "invalid price",
+			input: "title,price\nDune,cheap\nEmma,300\n",
+			want:  []wantRow{{row: 2, err: `invalid price "cheap"`}, {row: 3, title: "Emma"}},
+		},
+		{
+			name:  "quoted fields span lines",
+			input: "title,description\n\"Dune\",\"spice\nmelange\"\nEmma,\n",
+			want:  []wantRow{{row: 2, title: "Dune"}, {row: 4, title: "Emma"}},
+		},
+		{
+			name:  "malformed quoting",
+			input: "title\nDu\"ne\nEmma\n",
+			want:  []wantRow{{row: 2, err: "bare \" in non-quoted-field"}, {row: 3, title: "Emma"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := csvRows(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("csvRows: %v", err)
+			}
+			if err := checkRows(t, rows, tt.want); err != nil {
+				t.Errorf("iteration ended with %v", err)
+			}
+		})
+	}
+}
+
+func TestCSVRowsRejectsHeader(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty file":     "",
+		"unknown column": "title,isbn\nDune,9780441013593\n",
+		"no title":       "id,author\n1,Frank Herbert\n",
+	} {
+		if _, err := csvRows(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: csvRows succeeded, want an error", name)
+		}
+	}
+}
+
+// multipartUpload builds a multipart body with a "note" field followed by a
+// "file" part named fileName, unless fileName is empty.
+func multipartUpload(t *testing.T, fileName, content string) (string, *bytes.Buffer) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("note", "spring catalog"); err != nil {
+		t.Fatal(err)
+	}
+	if fileName != "" {
+		part, err := writer.CreateFormFile("file", fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return writer.FormDataContentType(), &body
+}
+
+func TestImportBooks(t *testing.T) {
+	csvType, csvUpload := multipartUpload(t, "books.csv", "title,price\nDune,900\nEmma,x\n")
+	jsonlType, jsonlUpload := multipartUpload(t, "books.jsonl", `{"title": "Dune"}`+"\n"+`{"title": "Emma"} {}`+"\n")
+	noFileType, noFileUpload := multipartUpload(t, "", "")
+	pdfType, pdfUpload := multipartUpload(t, "books.pdf", "%PDF")
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        *bytes.Buffer
+		wantStatus  int
+		wantBody    []string
+	}{
+		{
+			name:        "raw ndjson",
+			contentType: "application/x-ndjson",
+			body:        bytes.NewBufferString(`{"id": "1", "title": "Dup"}` + "\n" + `{"title": "New"}` + "\n" + `{"title": ""}` + "\n"),
+			wantStatus:  http.StatusOK,
+			wantBody:    []string{`"created":1`, `"skipped":1`, `"failed":1`, `{"row":3,"reason":"title is required"}`},
+		},
+		{
+			name:        "multipart csv",
+			contentType: csvType,
+			body:        csvUpload,
+			wantStatus:  http.StatusOK,
+			wantBody:    []string{`"created":1`, `"failed":1`, `{"row":3,"reason":"invalid price \"x\""}`},
+		},
+		{
+			name:        "multipart jsonl",
+			contentType: jsonlType,
+			body:        jsonlUpload,
+			wantStatus:  http.StatusOK,
+			wantBody:    []string{`"created":1`, `"failed":1`, `"row":2`},
+		},
+		{
+			name:        "multipart without file part",
+			contentType: noFileType,
+			body:        noFileUpload,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    []string{`no \"file\" part`},
+		},
+		{
+			name:        "multipart unsupported file",
+			contentType: pdfType,
+			body:        pdfUpload,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    []string{"unsupported import format"},
+		},
+		{
+			name:        "csv without title column",
+			contentType: "text/csv",
+			body:        bytes.NewBufferString("id,author\n1,Frank Herbert\n"),
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    []string{`must contain a \"title\" column`},
+		},
+		{
+			name:        "ndjson line too long",
+			contentType: "application/x-ndjson",
+			body:        bytes.NewBufferString(`{"title": "` + strings.Repeat("x", maxImportLineBytes) + `"}`),
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    []string{"could not read upload"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/books/import", tt.body)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			rec := serve(t, newFake(), req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body does not contain %s: %s", want, rec.Body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RegisterRoutes adds the book API routes to mux. The gRPC calls made for a
+// request share one deadline: routeTimeouts[pattern] when the route is listed,
+// defaultTimeout otherwise. A zero timeout means no deadline. Patterns in
+// routeTimeouts that name no route are reported as an error. Every route is
+// wrapped in middleware, outermost first, e.g. to require authentication.
+func (bc *BookController) RegisterRoutes(mux *http.ServeMux, defaultTimeout time.Duration, routeTimeouts map[string]time.Duration, middleware ...func(http.Handler) http.Handler) error {
+	routes := []struct {
+		pattern string
+		handler http.HandlerFunc
+	}{
+		{"GET /books/{id}", bc.FetchBookByID},
+		{"GET /books", bc.FetchAllBooks},
+		{"POST /books", bc.CreateBook},
+		{"GET /books/export", bc.ExportBooks},
+		{"GET /books/events", bc.StreamBookEvents},
+		{"POST /books:batchCreate", bc.BatchCreateBooks},
+		{"GET /books:batchGet", bc.BatchGetBooks},
+		{"POST /books/import", bc.ImportBooks},
+		{"PATCH /books/{id}", bc.UpdateBook},
+		{"DELETE /books/{id}", bc.DeleteBook},
+		{"POST /books/{name}", bc.UndeleteBook},
+	}
+
+	known := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		known[route.pattern] = true
+	}
+	for pattern := range routeTimeouts {
+		if !known[pattern] {
+			return fmt.Errorf("timeout configured for unknown route %q", pattern)
+		}
+	}
+
+	for _, route := range routes {
+		timeout, ok := routeTimeouts[route.pattern]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		var handler http.Handler = withTimeout(timeout, route.handler)
+		for i := len(middleware) - 1; i >= 0; i-- {
+			handler = middleware[i](handler)
+		}
+		mux.Handle(route.pattern, withRoute(route.pattern, handler))
+	}
+	return nil
+}
+
+// SpanName formats the name of the span of an HTTP request for otelhttp: the
+// route pattern once the request has been routed, the method before that.
+func SpanName(_ string, req *http.Request) string {
+	if req.Pattern != "" {
+		return req.Pattern
+	}
+	return req.Method
+}
+
+// withRoute names the span of the request after the route pattern, e.g.
+// "GET /books/{id}", and tags it with the http.route attribute. Middleware
+// that copies the request hides the pattern from otelhttp, so the span is
+// renamed here rather than by SpanName.
+func withRoute(pattern string, next http.Handler) http.Handler {
+	_, path, _ := strings.Cut(pattern, " ")
+	next = otelhttp.WithRouteTag(path, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		trace.SpanFromContext(req.Context()).SetName(pattern)
+		next.ServeHTTP(w, req)
+	})
+}
+
+// withTimeout bounds the request context of next by timeout, unless it is zero.
+func withTimeout(timeout time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	if timeout <= 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		next(w, req.WithContext(ctx))
+	}
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRouteTimeouts(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		defaultTimeout time.Duration
+		routeTimeouts  map[string]time.Duration
+		wantStatus     int
+	}{
+		{name: "no deadline", target: "/books/1", wantStatus: http.StatusOK},
+		{name: "default deadline exceeded", target: "/books/1", defaultTimeout: 10 * time.Millisecond, wantStatus: http.StatusGatewayTimeout},
+		{
+			name:           "route override extends deadline",
+			target:         "/books/1",
+			defaultTimeout: 10 * time.Millisecond,
+			routeTimeouts:  map[string]time.Duration{"GET /books/{id}": time.Minute},
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:           "route override disables deadline",
+			target:         "/books",
+			defaultTimeout: 10 * time.Millisecond,
+			routeTimeouts:  map[string]time.Duration{"GET /books": 0},
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:           "other routes keep default",
+			target:         "/books",
+			defaultTimeout: 10 * time.Millisecond,
+			routeTimeouts:  map[string]time.Duration{"GET /books/{id}": time.Minute},
+			wantStatus:     http.StatusGatewayTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFake()
+			fake.Latency = 100 * time.Millisecond
+			mux := http.NewServeMux()
+			if err := NewBookController(fake).RegisterRoutes(mux, tt.defaultTimeout, tt.routeTimeouts); err != nil {
+				t.Fatalf("RegisterRoutes: %v", err)
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestRouteTimeoutsUnknownRoute(t *testing.T) {
+	err := NewBookController(newFake()).RegisterRoutes(http.NewServeMux(), time.Second,
+		map[string]time.Duration{"GET /authors": time.Second})
+	if err == nil {
+		t.Fatal("RegisterRoutes accepted a timeout for an unknown route")
+	}
+}
+
+func TestRouteSpanNames(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	handler := otelhttp.NewHandler(newMux(t, newFake()), "test", otelhttp.WithTracerProvider(provider), otelhttp.WithSpanNameFormatter(SpanName))
+
+	for _, target := range []string{"/books/1", "/books/3:undelete"} {
+		method := http.MethodGet
+		if target == "/books/3:undelete" {
+			method = http.MethodPost
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+	}
+
+	want := map[string]string{"GET /books/{id}": "/books/{id}", "POST /books/{name}": "/books/{name}"}
+	for _, span := range recorder.Ended() {
+		route, ok := want[span.Name()]
+		if !ok {
+			t.Errorf("unexpected span %q", span.Name())
+			continue
+		}
+		delete(want, span.Name())
+		for _, attr := range span.Attributes() {
+			if attr.Key == "http.route" && attr.Value.AsString() != route {
+				t.Errorf("span %q has http.route %q, want %q", span.Name(), attr.Value.AsString(), route)
+			}
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing spans %v", want)
+	}
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	var order []string
+	tag := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+	deny := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+
+	mux := http.NewServeMux()
+	if err := NewBookController(newFake()).RegisterRoutes(mux, time.Second, nil, tag("outer"), tag("inner"), deny); err != nil {
+		t.Fatalf("RegisterRoutes: %v", err)
+	}
+	NewHealthController(newFake()).RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || len(order) != 2 || order[0] != "outer" {
+		t.Errorf("book route: status %d, middleware ran %v", rec.Code, order)
+	}
+	if req.Pattern != "GET /books/{id}" {
+		t.Errorf("Pattern = %q, want the route seen by outer middleware", req.Pattern)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/healthz status = %d, want 200 without middleware", rec.Code)
+	}
+}
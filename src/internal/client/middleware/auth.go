@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/auth"
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// Authenticate returns middleware that lets through requests authenticated by
+// authn and answers the others with 401 and a WWW-Authenticate challenge. The
+// principal is stored in the request context, where the gRPC client forwards
+// it to the server, and added to the context logger. It copies the request,
+// so it has to wrap route handlers rather than the mux.
+func Authenticate(authn *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			principal, err := authn.Authenticate(req)
+			if err != nil {
+				for _, challenge := range authn.Challenges(err) {
+					w.Header().Add("WWW-Authenticate", challenge)
+				}
+				message := "Authentication required"
+				if errors.Is(err, auth.ErrInvalidCredentials) {
+					message = "Invalid credentials"
+					utils.LoggerFromContext(req.Context()).Warn("Authentication failed", "error", err)
+				}
+				utils.JSONErrorResponse(w, http.StatusUnauthorized, message, nil)
+				return
+			}
+
+			ctx := auth.NewContext(req.Context(), principal)
+			ctx = utils.ContextWithLogger(ctx, utils.LoggerFromContext(ctx).With(auth.Attr(principal)))
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
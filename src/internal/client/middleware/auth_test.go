@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/auth"
+)
+
+func TestAuthenticate(t *testing.T) {
+	sum := sha256.Sum256([]byte("ci-secret"))
+	keys, err := auth.NewAPIKeys(map[string]string{"ci": hex.EncodeToString(sum[:])})
+	if err != nil {
+		t.Fatalf("NewAPIKeys: %v", err)
+	}
+	authn := auth.NewAuthenticator(keys, nil, "bookie")
+
+	tests := []struct {
+		name          string
+		key           string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{name: "valid key", key: "ci-secret", wantStatus: http.StatusOK, wantPrincipal: "ci"},
+		{name: "invalid key", key: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "no key", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := Authenticate(authn)(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				p, _ := auth.FromContext(req.Context())
+				seen = p.Subject
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/books", nil)
+			if tt.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus || seen != tt.wantPrincipal {
+				t.Errorf("status = %d, principal %q; want %d, %q", rec.Code, seen, tt.wantStatus, tt.wantPrincipal)
+			}
+			challenge := rec.Header().Get("WWW-Authenticate")
+			if (rec.Code == http.StatusUnauthorized) != (challenge != "") {
+				t.Errorf("status %d with WWW-Authenticate %q", rec.Code, challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Package middleware provides HTTP middleware for the bookie client application.
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// AccessLog logs every request with its route, status and duration, using the
+// logger of the request context. Requests the client abandoned before the
+// response was complete are logged with status 499, whatever the handler wrote.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, req)
+
+		code := sw.Status()
+		if errors.Is(req.Context().Err(), context.Canceled) {
+			code = utils.StatusClientClosedRequest
+		}
+		level := slog.LevelInfo
+		if code >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		utils.LoggerFromContext(req.Context()).LogAttrs(req.Context(), level, "HTTP request",
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.String("route", req.Pattern),
+			slog.Int("status", code),
+			slog.Duration("duration", time.Since(start)),
+		)
+	})
+}
+
+// statusWriter records the status code written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Status returns the status code sent, 200 when the handler wrote nothing.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush streamed responses.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		cancel     bool
+		wantStatus int
+	}{
+		{
+			name:       "implicit ok",
+			handler:    func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("ok")) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "gateway timeout",
+			handler:    func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusGatewayTimeout) },
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "client went away",
+			handler:    func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+			cancel:     true,
+			wantStatus: 499,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+			mux := http.NewServeMux()
+			mux.Handle("GET /books/{id}", tt.handler)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			ctx = utils.ContextWithLogger(ctx, logger)
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/books/1", nil)
+			AccessLog(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+			var entry struct {
+				Route  string `json:"route"`
+				Path   string `json:"path"`
+				Status int    `json:"status"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("decode log %q: %v", buf.String(), err)
+			}
+			if entry.Status != tt.wantStatus || entry.Route != "GET /books/{id}" || entry.Path != "/books/1" {
+				t.Errorf("logged %+v, want status %d on route GET /books/{id}", entry, tt.wantStatus)
+			}
+		})
+	}
+}
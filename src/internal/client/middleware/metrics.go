@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// unmatchedRoute labels requests that matched no route, so unknown paths do
+// not each create a series.
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of HTTP requests completed, by route pattern and status.",
+	}, []string{"route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to complete HTTP requests, by route pattern and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "status"})
+
+	httpInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests being served, including open event streams.",
+	})
+)
+
+// Metrics records Prometheus metrics for every request, labelled by the mux
+// pattern of its route, e.g. "GET /books/{id}". It must see the request the
+// mux routes, so it has to be the innermost middleware. Abandoned requests are
+// counted with status 499, like AccessLog logs them.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, req)
+
+		code := sw.Status()
+		if errors.Is(req.Context().Err(), context.Canceled) {
+			code = utils.StatusClientClosedRequest
+		}
+		route := req.Pattern
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(code)
+		httpRequests.WithLabelValues(route, status).Inc()
+		httpDuration.WithLabelValues(route, status).Observe(time.Since(start).Seconds())
+	})
+}
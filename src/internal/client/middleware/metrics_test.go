@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /books/{id}", func(w http.ResponseWriter, req *http.Request) {
+		if req.PathValue("id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	handler := Metrics(mux)
+
+	tests := []struct {
+		target string
+		cancel bool
+		route  string
+		status string
+	}{
+		{target: "/books/1", route: "GET /books/{id}", status: "200"},
+		{target: "/books/missing", route: "GET /books/{id}", status: "404"},
+		{target: "/books/2", cancel: true, route: "GET /books/{id}", status: "499"},
+		{target: "/nowhere/1", route: unmatchedRoute, status: "404"},
+	}
+	for _, tt := range tests {
+		counter := httpRequests.WithLabelValues(tt.route, tt.status)
+		before := testutil.ToFloat64(counter)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if tt.cancel {
+			cancel()
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequestWithContext(ctx, http.MethodGet, tt.target, nil))
+		cancel()
+
+		if got := testutil.ToFloat64(counter) - before; got != 1 {
+			t.Errorf("GET %s: count of route %q status %s grew by %v, want 1", tt.target, tt.route, tt.status, got)
+		}
+	}
+	if got := testutil.ToFloat64(httpInFlight); got != 0 {
+		t.Errorf("in-flight requests = %v after all returned", got)
+	}
+}
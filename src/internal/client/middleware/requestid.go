@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/requestid"
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// RequestID accepts the caller's X-Request-ID, or generates one when it is
+// missing or malformed, and echoes it in the response. The id is stored in the
+// request context, where the gRPC client forwards it to the server, together
+// with a logger that adds it to every line.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+
+		ctx := requestid.NewContext(req.Context(), id)
+		ctx = utils.ContextWithLogger(ctx, utils.LoggerFromContext(ctx).With(requestid.Attr(id)))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
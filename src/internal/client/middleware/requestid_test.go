@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool // the caller's id is used as is
+	}{
+		{name: "keeps valid id", header: "req-42", keep: true},
+		{name: "generates missing id"},
+		{name: "replaces invalid id", header: "two words"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := RequestID(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				seen = requestid.FromContext(req.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/books", nil)
+			if tt.header != "" {
+				req.Header.Set(requestid.Header, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			echoed := rec.Header().Get(requestid.Header)
+			if echoed != seen || !requestid.Valid(echoed) {
+				t.Errorf("echoed %q, handler saw %q", echoed, seen)
+			}
+			if (echoed == tt.header) != tt.keep {
+				t.Errorf("echoed %q for header %q, keep = %v", echoed, tt.header, tt.keep)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Package config loads runtime settings for the bookie binaries from the environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server holds the settings of the gRPC server.
+type Server struct {
+	// Port is the TCP port the gRPC server listens on.
+	Port string
+	// AdminPort is the TCP port of the admin HTTP server serving /metrics.
+	AdminPort string
+	// Debug enables gRPC server reflection on Port and the debug endpoints
+	// (pprof, channelz, config dump) on AdminPort. Keep it off in production.
+	Debug bool
+	// DBPath is the SQLite database file. When empty the server keeps books in memory.
+	DBPath string
+	// DeletedRetention is how long soft-deleted books are kept before being purged.
+	DeletedRetention time.Duration
+	// PurgeInterval is how often the server looks for soft-deleted books to purge.
+	PurgeInterval time.Duration
+	// HealthCheckInterval is how often storage readiness is checked for the
+	// gRPC health service.
+	HealthCheckInterval time.Duration
+	// Tracing configures the export of OpenTelemetry spans.
+	Tracing Tracing
+	// TLS configures the certificates of the gRPC listener.
+	TLS ServerTLS
+}
+
+// ServerTLS holds the TLS settings of the gRPC server. The server speaks
+// plaintext when CertFile is empty.
+type ServerTLS struct {
+	// CertFile and KeyFile are the PEM certificate chain and key of the server.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is a PEM bundle of the CAs that sign client certificates.
+	// When set, clients must present such a certificate (mutual TLS).
+	ClientCAFile string
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+// Enabled reports whether the server serves TLS.
+func (t ServerTLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// LoadServer reads the gRPC server settings from environment variables.
+func LoadServer() (Server, error) {
+	cfg := Server{
+		Port:      getEnv("PORT", "8020"),
+		AdminPort: getEnv("ADMIN_PORT", "9020"),
+		DBPath:    os.Getenv("DB_PATH"),
+	}
+
+	var err error
+	if cfg.Debug, err = getEnvBool("DEBUG_ENDPOINTS", false); err != nil {
+		return Server{}, err
+	}
+	if cfg.DeletedRetention, err = getEnvDuration("DELETED_BOOK_RETENTION", 30*24*time.Hour); err != nil {
+		return Server{}, err
+	}
+	if cfg.PurgeInterval, err = getEnvDuration("PURGE_INTERVAL", time.Hour); err != nil {
+		return Server{}, err
+	}
+	if cfg.HealthCheckInterval, err = getEnvDuration("HEALTH_CHECK_INTERVAL", 10*time.Second); err != nil {
+		return Server{}, err
+	}
+	if cfg.Tracing, err = loadTracing(); err != nil {
+		return Server{}, err
+	}
+	cfg.TLS = ServerTLS{
+		CertFile:     os.Getenv("TLS_CERT_FILE"),
+		KeyFile:      os.Getenv("TLS_KEY_FILE"),
+		ClientCAFile: os.Getenv("TLS_CLIENT_CA_FILE"),
+	}
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return Server{}, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if cfg.TLS.ClientCAFile != "" && !cfg.TLS.Enabled() {
+		return Server{}, errors.New("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+	if cfg.TLS.ReloadInterval, err = getEnvDuration("TLS_RELOAD_INTERVAL", 30*time.Second); err != nil {
+		return Server{}, err
+	}
+	return cfg, nil
+}
+
+// Client holds the settings of the HTTP client (BFF).
+type Client struct {
+	// Port is the TCP port the HTTP server listens on.
+	Port string
+	// AdminPort is the TCP port of the admin HTTP server serving /metrics.
+	AdminPort string
+	// Debug enables the debug endpoints (pprof, channelz, config dump) on
+	// AdminPort. Keep it off in production.
+	Debug bool
+	// GRPCServerAddr is the address of the Bookie gRPC server.
+	GRPCServerAddr string
+	// RequestTimeout bounds the gRPC calls made for one HTTP request.
+	RequestTimeout time.Duration
+	// RouteTimeouts overrides RequestTimeout per route, keyed by mux pattern
+	// such as "GET /books/export". Zero means no deadline.
+	RouteTimeouts map[string]time.Duration
+	// Tracing configures the export of OpenTelemetry spans.
+	Tracing Tracing
+	// TLS configures how the gRPC server is dialled.
+	TLS ClientTLS
+	// Auth configures how callers of the book routes authenticate.
+	Auth Auth
+}
+
+// Auth holds the credentials accepted by the HTTP client. The book routes
+// are open to anyone when none are configured.
+type Auth struct {
+	// APIKeys maps the name of each API key to the hex SHA-256 digest of the
+	// key. Names identify the caller in logs and on the gRPC server.
+	APIKeys map[string]string `redact:"true"`
+	// JWKSFile is a JSON Web Key Set whose keys sign accepted JWT bearer
+	// tokens. Tokens are not accepted when it is empty.
+	JWKSFile string
+	// JWTIssuer and JWTAudience, when set, must match the "iss" and "aud"
+	// claims of tokens.
+	JWTIssuer   string
+	JWTAudience string
+}
+
+// Enabled reports whether the book routes require authentication.
+func (a Auth) Enabled() bool {
+	return len(a.APIKeys) > 0 || a.JWKSFile != ""
+}
+
+// ClientTLS holds the TLS settings used to dial the gRPC server.
+type ClientTLS struct {
+	// Enabled dials the server over TLS instead of plaintext.
+	Enabled bool
+	// CAFile is a PEM bundle of the CAs that sign the server certificate.
+	// The system roots are used when it is empty.
+	CAFile string
+	// CertFile and KeyFile are the PEM certificate chain and key presented to
+	// servers that require mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name the server certificate is verified
+	// against, which is the host of GRPCServerAddr by default.
+	ServerName string
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+// defaultRouteTimeouts give the streaming routes room to move a whole
+// catalog. Live event streams have no deadline at all.
+var defaultRouteTimeouts = map[string]time.Duration{
+	"GET /books/export":  5 * time.Minute,
+	"GET /books/events":  0,
+	"POST /books/import": 10 * time.Minute,
+}
+
+// LoadClient reads the HTTP client settings from environment variables.
+// ROUTE_TIMEOUTS lists per-route overrides as comma-separated pattern=duration
+// pairs, e.g. "GET /books/export=10m,GET /books/events=0".
+func LoadClient() (Client, error) {
+	cfg := Client{
+		Port:           getEnv("HTTP_PORT", "8080"),
+		AdminPort:      getEnv("ADMIN_PORT", "9080"),
+		GRPCServerAddr: getEnv("GRPC_SERVER_ADDR", "localhost:8020"),
+		RouteTimeouts:  map[string]time.Duration{},
+	}
+
+	var err error
+	if cfg.Debug, err = getEnvBool("DEBUG_ENDPOINTS", false); err != nil {
+		return Client{}, err
+	}
+	if cfg.RequestTimeout, err = getEnvDuration("REQUEST_TIMEOUT", 10*time.Second); err != nil {
+		return Client{}, err
+	}
+	if cfg.Tracing, err = loadTracing(); err != nil {
+		return Client{}, err
+	}
+	if cfg.TLS.Enabled, err = getEnvBool("GRPC_TLS", false); err != nil {
+		return Client{}, err
+	}
+	cfg.TLS.CAFile = os.Getenv("GRPC_TLS_CA_FILE")
+	cfg.TLS.CertFile = os.Getenv("GRPC_TLS_CERT_FILE")
+	cfg.TLS.KeyFile = os.Getenv("GRPC_TLS_KEY_FILE")
+	cfg.TLS.ServerName = os.Getenv("GRPC_TLS_SERVER_NAME")
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return Client{}, errors.New("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be set together")
+	}
+	if !cfg.TLS.Enabled && (cfg.TLS.CAFile != "" || cfg.TLS.CertFile != "") {
+		return Client{}, errors.New("GRPC_TLS_* files are set but GRPC_TLS is not true")
+	}
+	if cfg.TLS.ReloadInterval, err = getEnvDuration("TLS_RELOAD_INTERVAL", 30*time.Second); err != nil {
+		return Client{}, err
+	}
+	if cfg.Auth, err = loadAuth(); err != nil {
+		return Client{}, err
+	}
+	for pattern, timeout := range defaultRouteTimeouts {
+		cfg.RouteTimeouts[pattern] = timeout
+	}
+	for _, entry := range strings.Split(os.Getenv("ROUTE_TIMEOUTS"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		pattern, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Client{}, fmt.Errorf("invalid ROUTE_TIMEOUTS entry %q: want pattern=duration", entry)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout < 0 {
+			return Client{}, fmt.Errorf("invalid ROUTE_TIMEOUTS duration for %q: %q", pattern, value)
+		}
+		cfg.RouteTimeouts[strings.Join(strings.Fields(pattern), " ")] = timeout
+	}
+	return cfg, nil
+}
+
+// loadAuth reads API_KEYS, JWT_JWKS_FILE, JWT_ISSUER and JWT_AUDIENCE.
+// API_KEYS lists comma-separated name:sha256 pairs, e.g. "ci:9f86d0...".
+func loadAuth() (Auth, error) {
+	cfg := Auth{
+		APIKeys:     map[string]string{},
+		JWKSFile:    os.Getenv("JWT_JWKS_FILE"),
+		JWTIssuer:   os.Getenv("JWT_ISSUER"),
+		JWTAudience: os.Getenv("JWT_AUDIENCE"),
+	}
+	for _, entry := range strings.Split(os.Getenv("API_KEYS"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, hash, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || name == "" || hash == "" {
+			return Auth{}, fmt.Errorf("invalid API_KEYS entry %q: want name:sha256", entry)
+		}
+		if _, dup := cfg.APIKeys[name]; dup {
+			return Auth{}, fmt.Errorf("duplicate API_KEYS name %q", name)
+		}
+		cfg.APIKeys[name] = strings.ToLower(hash)
+	}
+	if cfg.JWKSFile == "" && (cfg.JWTIssuer != "" || cfg.JWTAudience != "") {
+		return Auth{}, errors.New("JWT_ISSUER and JWT_AUDIENCE require JWT_JWKS_FILE")
+	}
+	return cfg, nil
+}
+
+// Span exporters selectable with TRACES_EXPORTER.
+const (
+	TracesExporterNone     = "none"
+	TracesExporterStdout   = "stdout"
+	TracesExporterOTLPFile = "otlp-file"
+)
+
+// Tracing holds the OpenTelemetry settings shared by both binaries.
+type Tracing struct {
+	// Exporter is where finished spans go: TracesExporterNone, TracesExporterStdout
+	// or TracesExporterOTLPFile. Trace context is propagated in every case.
+	Exporter string
+	// File is the file the otlp-file exporter appends spans to, one OTLP/JSON
+	// export request per line.
+	File string
+}
+
+// loadTracing reads TRACES_EXPORTER and TRACES_FILE.
+func loadTracing() (Tracing, error) {
+	cfg := Tracing{
+		Exporter: getEnv("TRACES_EXPORTER", TracesExporterNone),
+		File:     os.Getenv("TRACES_FILE"),
+	}
+	switch cfg.Exporter {
+	case TracesExporterNone, TracesExporterStdout:
+	case TracesExporterOTLPFile:
+		if cfg.File == "" {
+			return Tracing{}, fmt.Errorf("TRACES_FILE is required when TRACES_EXPORTER is %s", TracesExporterOTLPFile)
+		}
+	default:
+		return Tracing{}, fmt.Errorf("invalid TRACES_EXPORTER %q: want %s, %s or %s",
+			cfg.Exporter, TracesExporterNone, TracesExporterStdout, TracesExporterOTLPFile)
+	}
+	return cfg, nil
+}
+
+// getEnv returns the value of the environment variable key or fallback when it is unset or empty.
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvDuration parses the environment variable key as a time.Duration, e.g. "90s" or "24h".
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be positive", key)
+	}
+	return d, nil
+}
+
+// getEnvBool parses the environment variable key as a boolean, e.g. "true" or "0".
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q is not a boolean", key, value)
+	}
+	return b, nil
+}
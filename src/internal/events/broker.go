@@ -0,0 +1,135 @@
+// Package events fans out book change events to WatchBooks subscribers.
+package events
+
+import (
+	"errors"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+var (
+	// ErrRevisionCompacted is returned when resuming from a revision that is
+	// no longer held in the broker history.
+	ErrRevisionCompacted = errors.New("revision has been compacted")
+	// ErrFutureRevision is returned when resuming from a revision that has not
+	// been issued yet, typically because the server restarted.
+	ErrFutureRevision = errors.New("revision has not been issued")
+	// ErrClosed is returned when subscribing to a closed broker.
+	ErrClosed = errors.New("broker is closed")
+)
+
+// Broker assigns revisions to book events, keeps a bounded history for
+// resuming subscribers and delivers new events without ever blocking the
+// publisher. Subscribers that cannot keep up are dropped.
+type Broker struct {
+	mu         sync.Mutex
+	revision   int64
+	history    []*bookiePb.BookEvent
+	historyCap int
+	bufferSize int
+	subs       map[*Subscription]struct{}
+	closed     bool
+}
+
+// NewBroker creates a Broker that remembers the last historySize events and
+// buffers up to bufferSize undelivered events per subscriber.
+func NewBroker(historySize, bufferSize int) *Broker {
+	return &Broker{
+		historyCap: historySize,
+		bufferSize: bufferSize,
+		subs:       make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish records a change to book and delivers it to every subscriber.
+func (b *Broker) Publish(eventType bookiePb.BookEvent_Type, book *bookiePb.Book) *bookiePb.BookEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.revision++
+	event := &bookiePb.BookEvent{
+		Type:      eventType,
+		Revision:  b.revision,
+		Book:      proto.Clone(book).(*bookiePb.Book),
+		EventTime: timestamppb.Now(),
+	}
+
+	b.history = append(b.history, event)
+	if len(b.history) > b.historyCap {
+		b.history = b.history[len(b.history)-b.historyCap:]
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.events <- event:
+		default:
+			sub.end(ErrSlowConsumer)
+			delete(b.subs, sub)
+		}
+	}
+	return event
+}
+
+// Subscribe starts a subscription that first replays the retained events
+// after afterRevision and then receives new ones. Zero skips the replay.
+func (b *Broker) Subscribe(afterRevision int64) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	var backlog []*bookiePb.BookEvent
+	if afterRevision > 0 {
+		if afterRevision > b.revision {
+			return nil, ErrFutureRevision
+		}
+		oldest := b.revision - int64(len(b.history)) + 1
+		if afterRevision+1 < oldest {
+			return nil, ErrRevisionCompacted
+		}
+		backlog = b.history[len(b.history)-int(b.revision-afterRevision):]
+	}
+
+	sub := &Subscription{
+		broker: b,
+		events: make(chan *bookiePb.BookEvent, len(backlog)+b.bufferSize),
+		done:   make(chan struct{}),
+	}
+	for _, event := range backlog {
+		sub.events <- event
+	}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Revision returns the revision of the latest published event.
+func (b *Broker) Revision() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.revision
+}
+
+// Close ends every subscription with ErrClosed and rejects new ones, so
+// watch streams finish and the gRPC server can stop gracefully.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		sub.end(ErrClosed)
+		delete(b.subs, sub)
+	}
+}
+
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, sub)
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+func publishN(b *Broker, n int) {
+	for i := 0; i < n; i++ {
+		b.Publish(bookiePb.BookEvent_CREATED, &bookiePb.Book{Id: "id"})
+	}
+}
+
+func TestSubscribeReplaysFromRevision(t *testing.T) {
+	b := NewBroker(10, 4)
+	publishN(b, 5)
+
+	sub, err := b.Subscribe(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	publishN(b, 1)
+
+	for want := int64(4); want <= 6; want++ {
+		if got := (<-sub.Events()).GetRevision(); got != want {
+			t.Fatalf("got revision %d, want %d", got, want)
+		}
+	}
+}
+
+func TestSubscribeRejectsUnknownRevisions(t *testing.T) {
+	b := NewBroker(3, 4)
+	publishN(b, 5) // history holds revisions 3..5
+
+	if _, err := b.Subscribe(1); !errors.Is(err, ErrRevisionCompacted) {
+		t.Errorf("Subscribe(1) = %v, want ErrRevisionCompacted", err)
+	}
+	if _, err := b.Subscribe(6); !errors.Is(err, ErrFutureRevision) {
+		t.Errorf("Subscribe(6) = %v, want ErrFutureRevision", err)
+	}
+	if _, err := b.Subscribe(2); err != nil {
+		t.Errorf("Subscribe(2) = %v, want nil", err)
+	}
+}
+
+func TestSlowConsumerIsDropped(t *testing.T) {
+	b := NewBroker(10, 2)
+	sub, err := b.Subscribe(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publishN(b, 3) // never read; the third event overflows the buffer
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("subscription still open after its buffer overflowed")
+	}
+	if !errors.Is(sub.Err(), ErrSlowConsumer) {
+		t.Errorf("Err() = %v, want ErrSlowConsumer", sub.Err())
+	}
+}
+
+func TestCloseEndsSubscriptions(t *testing.T) {
+	b := NewBroker(10, 2)
+	sub, err := b.Subscribe(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.Close()
+	<-sub.Done()
+	if !errors.Is(sub.Err(), ErrClosed) {
+		t.Errorf("Err() = %v, want ErrClosed", sub.Err())
+	}
+	if _, err := b.Subscribe(0); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe after Close = %v, want ErrClosed", err)
+	}
+}
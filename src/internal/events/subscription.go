@@ -0,0 +1,54 @@
+package events
+
+import (
+	"errors"
+	"sync"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+// ErrSlowConsumer ends a subscription whose buffer filled up.
+var ErrSlowConsumer = errors.New("subscriber fell behind")
+
+// Subscription is one consumer of the broker's events.
+type Subscription struct {
+	broker *Broker
+	events chan *bookiePb.BookEvent
+
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+// Events delivers events in revision order.
+func (s *Subscription) Events() <-chan *bookiePb.BookEvent {
+	return s.events
+}
+
+// Done is closed when the broker ends the subscription; Err then reports why.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns ErrSlowConsumer or ErrClosed once Done is closed, nil before.
+func (s *Subscription) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Close stops delivery to the subscription.
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+// end is called with the broker lock held.
+func (s *Subscription) end(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
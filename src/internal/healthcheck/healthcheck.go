@@ -0,0 +1,55 @@
+// Package healthcheck probes a running bookie binary. It backs the
+// "healthcheck" subcommand used by container health checks, since the
+// distroless images ship neither a shell nor curl.
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Timeout bounds one probe.
+const Timeout = 3 * time.Second
+
+// GRPC asks the grpc.health.v1.Health service at addr for the status of
+// service, "" meaning the whole server, and fails unless it is SERVING. The
+// connection is secured with creds.
+func GRPC(ctx context.Context, addr, service string, creds credentials.TransportCredentials) error {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return err
+	}
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("status %s", res.GetStatus())
+	}
+	return nil
+}
+
+// HTTP fetches url and fails unless it answers 200 OK.
+func HTTP(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %s", res.Status)
+	}
+	return nil
+}
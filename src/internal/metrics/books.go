@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/repository"
+)
+
+// booksScrapeTimeout bounds the repository call made on every scrape.
+const booksScrapeTimeout = 5 * time.Second
+
+var booksDesc = prometheus.NewDesc(
+	"bookie_books",
+	"Number of books in the store, by state (live or deleted).",
+	[]string{"state"}, nil,
+)
+
+// booksCollector reports the number of stored books at scrape time.
+type booksCollector struct {
+	repo repository.BookRepository
+}
+
+// NewBooksCollector returns a collector of the bookie_books gauge, read from
+// repo whenever metrics are scraped.
+func NewBooksCollector(repo repository.BookRepository) prometheus.Collector {
+	return &booksCollector{repo: repo}
+}
+
+func (c *booksCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- booksDesc
+}
+
+func (c *booksCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), booksScrapeTimeout)
+	defer cancel()
+
+	live, deleted, err := c.repo.Count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(booksDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(booksDesc, prometheus.GaugeValue, float64(live), "live")
+	ch <- prometheus.MustNewConstMetric(booksDesc, prometheus.GaugeValue, float64(deleted), "deleted")
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/repository"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/Bookie/GetByID"}
+	notFound := rpcsHandled.WithLabelValues(unary, "Bookie", "GetByID", codes.NotFound.String())
+	ok := rpcsHandled.WithLabelValues(unary, "Bookie", "GetByID", codes.OK.String())
+	before := testutil.ToFloat64(notFound)
+
+	_, _ = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "Book with ID 1 not found")
+	})
+	_, _ = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	_, _ = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+
+	if got := testutil.ToFloat64(notFound) - before; got != 1 {
+		t.Errorf("NotFound count grew by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(ok); got != 2 {
+		t.Errorf("OK count = %v, want 2", got)
+	}
+	if n := testutil.CollectAndCount(rpcDuration, "grpc_server_handling_seconds"); n < 2 {
+		t.Errorf("histogram has %d series, want one per code", n)
+	}
+}
+
+func TestStreamType(t *testing.T) {
+	tests := []struct {
+		info *grpc.StreamServerInfo
+		want string
+	}{
+		{&grpc.StreamServerInfo{IsServerStream: true}, serverStream},
+		{&grpc.StreamServerInfo{IsClientStream: true}, clientStream},
+		{&grpc.StreamServerInfo{IsClientStream: true, IsServerStream: true}, bidiStream},
+	}
+	for _, tt := range tests {
+		if got := streamType(tt.info); got != tt.want {
+			t.Errorf("streamType(%+v) = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	tests := []struct {
+		fullMethod, service, method string
+	}{
+		{"/Bookie/GetByID", "Bookie", "GetByID"},
+		{"/grpc.health.v1.Health/Check", "grpc.health.v1.Health", "Check"},
+		{"garbage", "unknown", "unknown"},
+	}
+	for _, tt := range tests {
+		service, method := splitMethod(tt.fullMethod)
+		if service != tt.service || method != tt.method {
+			t.Errorf("splitMethod(%q) = %q, %q, want %q, %q", tt.fullMethod, service, method, tt.service, tt.method)
+		}
+	}
+}
+
+func TestBooksCollector(t *testing.T) {
+	repo := repository.NewMemoryRepository([]*bookiePb.Book{
+		{Id: "1", Title: "Dune"},
+		{Id: "2", Title: "Emma"},
+		{Id: "3", Title: "Lost", DeleteTime: timestamppb.Now()},
+	})
+
+	want := `
+# HELP bookie_books Number of books in the store, by state (live or deleted).
+# TYPE bookie_books gauge
+bookie_books{state="deleted"} 1
+bookie_books{state="live"} 2
+`
+	if err := testutil.CollectAndCompare(NewBooksCollector(repo), strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"fmt"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+// fieldKind is the type of value a book field holds.
+type fieldKind int
+
+const (
+	stringField fieldKind = iota
+	intField
+)
+
+// field describes a book field that can be filtered and sorted on.
+type field struct {
+	kind fieldKind
+	str  func(*bookiePb.Book) string
+	num  func(*bookiePb.Book) int64
+}
+
+// fields lists the book fields usable in filter and order_by expressions.
+var fields = map[string]field{
+	"id":          {kind: stringField, str: (*bookiePb.Book).GetId},
+	"title":       {kind: stringField, str: (*bookiePb.Book).GetTitle},
+	"description": {kind: stringField, str: (*bookiePb.Book).GetDescription},
+	"author":      {kind: stringField, str: (*bookiePb.Book).GetAuthor},
+	"price":       {kind: intField, num: (*bookiePb.Book).GetPrice},
+}
+
+// compare orders a and b by the field value.
+func (f field) compare(a, b *bookiePb.Book) int {
+	if f.kind == intField {
+		return compareOrdered(f.num(a), f.num(b))
+	}
+	return compareOrdered(f.str(a), f.str(b))
+}
+
+func compareOrdered[T int64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SyntaxError reports a malformed filter or order_by expression.
+type SyntaxError struct {
+	// Pos is the 1-based character position of the offending input.
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
@@ -0,0 +1,198 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+// Filter is a compiled filter expression. A nil Filter matches every book.
+//
+// The grammar is a small subset of AIP-160:
+//
+//	expr       = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" expr ")" | comparison
+//	comparison = field op value
+//	op         = "=" | "!=" | "<" | "<=" | ">" | ">=" | ":"
+//
+// Strings are double or single quoted, numbers are integers. The ":" operator
+// is a case-insensitive substring match and only applies to string fields.
+// For example: author = "JK Rowling" AND price < 500.
+type Filter struct {
+	match func(*bookiePb.Book) bool
+}
+
+// Match reports whether book satisfies the filter.
+func (f *Filter) Match(book *bookiePb.Book) bool {
+	if f == nil {
+		return true
+	}
+	return f.match(book)
+}
+
+// ParseFilter compiles a filter expression. An empty expression yields a nil Filter.
+func ParseFilter(input string) (*Filter, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return &Filter{match: match}, nil
+}
+
+type predicate = func(*bookiePb.Book) bool
+
+type filterParser struct {
+	tokens []token
+	next   int
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *filterParser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && tok.text == word
+}
+
+func (p *filterParser) parseOr() (predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(b *bookiePb.Book) bool { return l(b) || right(b) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(b *bookiePb.Book) bool { return l(b) && right(b) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (predicate, error) {
+	switch tok := p.peek(); {
+	case tok.kind == tokIdent && tok.text == "NOT":
+		p.advance()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(b *bookiePb.Book) bool { return !inner(b) }, nil
+	case tok.kind == tokLParen:
+		p.advance()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokRParen {
+			return nil, &SyntaxError{Pos: closing.pos, Msg: "expected ')'"}
+		}
+		return inner, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *filterParser) parseComparison() (predicate, error) {
+	name := p.advance()
+	if name.kind != tokIdent {
+		return nil, &SyntaxError{Pos: name.pos, Msg: "expected a field name"}
+	}
+	f, ok := fields[name.text]
+	if !ok {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("unknown field %q", name.text)}
+	}
+
+	op := p.advance()
+	if op.kind != tokOp {
+		return nil, &SyntaxError{Pos: op.pos, Msg: "expected a comparison operator"}
+	}
+
+	value := p.advance()
+	switch f.kind {
+	case intField:
+		if value.kind != tokNumber {
+			return nil, &SyntaxError{Pos: value.pos, Msg: fmt.Sprintf("field %q expects a number", name.text)}
+		}
+		if op.text == ":" {
+			return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("operator ':' is not supported on field %q", name.text)}
+		}
+		n, err := strconv.ParseInt(value.text, 10, 64)
+		if err != nil {
+			return nil, &SyntaxError{Pos: value.pos, Msg: "number out of range"}
+		}
+		return compareWith(op.text, func(b *bookiePb.Book) int { return compareOrdered(f.num(b), n) }), nil
+	default:
+		if value.kind != tokString {
+			return nil, &SyntaxError{Pos: value.pos, Msg: fmt.Sprintf("field %q expects a quoted string", name.text)}
+		}
+		if op.text == ":" {
+			needle := strings.ToLower(value.text)
+			return func(b *bookiePb.Book) bool {
+				return strings.Contains(strings.ToLower(f.str(b)), needle)
+			}, nil
+		}
+		return compareWith(op.text, func(b *bookiePb.Book) int { return compareOrdered(f.str(b), value.text) }), nil
+	}
+}
+
+// compareWith turns a three-way comparison into a predicate for op.
+func compareWith(op string, cmp func(*bookiePb.Book) int) predicate {
+	switch op {
+	case "=":
+		return func(b *bookiePb.Book) bool { return cmp(b) == 0 }
+	case "!=":
+		return func(b *bookiePb.Book) bool { return cmp(b) != 0 }
+	case "<":
+		return func(b *bookiePb.Book) bool { return cmp(b) < 0 }
+	case "<=":
+		return func(b *bookiePb.Book) bool { return cmp(b) <= 0 }
+	case ">":
+		return func(b *bookiePb.Book) bool { return cmp(b) > 0 }
+	default: // ">="
+		return func(b *bookiePb.Book) bool { return cmp(b) >= 0 }
+	}
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+func TestFilterMatch(t *testing.T) {
+	rowling := &bookiePb.Book{Id: "1", Title: "Harry Potter", Author: "JK Rowling", Price: 120}
+	other := &bookiePb.Book{Id: "2", Title: "Game of life", Author: "Author Two", Price: 450}
+
+	tests := []struct {
+		filter string
+		want   []bool // matches for rowling, other
+	}{
+		{``, []bool{true, true}},
+		{`author = "JK Rowling" AND price < 500`, []bool{true, false}},
+		{`price >= 450`, []bool{false, true}},
+		{`price != 120`, []bool{false, true}},
+		{`title : 'potter'`, []bool{true, false}},
+		{`NOT author = "JK Rowling"`, []bool{false, true}},
+		{`price < 100 OR (title : "life" AND price <= 450)`, []bool{false, true}},
+		{`author = "JK \"The\" Rowling"`, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.filter, err)
+			continue
+		}
+		for i, book := range []*bookiePb.Book{rowling, other} {
+			if got := f.Match(book); got != tt.want[i] {
+				t.Errorf("ParseFilter(%q).Match(%s) = %v, want %v", tt.filter, book.GetId(), got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFilterSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		pos    int
+	}{
+		{`colour = "red"`, 1},
+		{`price < "cheap"`, 9},
+		{`author = 5`, 10},
+		{`price : 5`, 7},
+		{`author = "JK`, 10},
+		{`price < 500 AND`, 16},
+		{`(price < 500`, 13},
+		{`price < 500 title = "x"`, 13},
+		{`price ! 5`, 7},
+		{`price # 5`, 7},
+	}
+	for _, tt := range tests {
+		_, err := ParseFilter(tt.filter)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("ParseFilter(%q) error = %v, want SyntaxError", tt.filter, err)
+			continue
+		}
+		if syntaxErr.Pos != tt.pos {
+			t.Errorf("ParseFilter(%q) position = %d (%v), want %d", tt.filter, syntaxErr.Pos, err, tt.pos)
+		}
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	order, err := ParseOrderBy("price desc, title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := order.String(), "price desc, title, id"; got != want {
+		t.Errorf("ParseOrderBy = %q, want %q", got, want)
+	}
+
+	for input, pos := range map[string]int{
+		"price sideways": 7,
+		"price,":         7,
+		"price, price":   8,
+		"rating":         1,
+	} {
+		var syntaxErr *SyntaxError
+		if _, err := ParseOrderBy(input); !errors.As(err, &syntaxErr) || syntaxErr.Pos != pos {
+			t.Errorf("ParseOrderBy(%q) error = %v, want SyntaxError at %d", input, err, pos)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// pos is the 1-based character position of the token in the input.
+	pos int
+}
+
+// tokenize splits a filter or order_by expression into tokens.
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: pos})
+			i++
+		case r == '"' || r == '\'':
+			text, next, err := scanString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: text, pos: pos})
+			i = next
+		case r == '-' || unicode.IsDigit(r):
+			j := i + 1
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			if r == '-' && j == i+1 {
+				return nil, &SyntaxError{Pos: pos, Msg: "expected digits after '-'"}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[i:j]), pos: pos})
+			i = j
+		case strings.ContainsRune("=!<>:", r):
+			j := i + 1
+			if j < len(runes) && runes[j] == '=' && r != '=' && r != ':' {
+				j++
+			}
+			op := string(runes[i:j])
+			if op == "!" {
+				return nil, &SyntaxError{Pos: pos, Msg: "unexpected '!', did you mean '!='"}
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: pos})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[i:j]), pos: pos})
+			i = j
+		default:
+			return nil, &SyntaxError{Pos: pos, Msg: "unexpected character " + string(r)}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes) + 1}), nil
+}
+
+// scanString reads a quoted string starting at runes[start] and returns its
+// unescaped value and the index just past the closing quote.
+func scanString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 == len(runes) {
+				return "", 0, &SyntaxError{Pos: i + 1, Msg: "unterminated escape sequence"}
+			}
+			i++
+			b.WriteRune(runes[i])
+		case quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteRune(runes[i])
+		}
+	}
+	return "", 0, &SyntaxError{Pos: start + 1, Msg: "unterminated string"}
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+// OrderField is one sort key of an order_by expression.
+type OrderField struct {
+	Field string
+	Desc  bool
+}
+
+// Ordering is a parsed order_by expression. Orderings returned by
+// ParseOrderBy always end with id, so they are total orders suitable for
+// pagination.
+type Ordering []OrderField
+
+// ParseOrderBy parses a comma-separated list of fields, each optionally
+// followed by "asc" or "desc", e.g. "price desc, title". An empty expression
+// orders by id.
+func ParseOrderBy(input string) (Ordering, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var ordering Ordering
+	seen := map[string]bool{}
+	for i := 0; tokens[i].kind != tokEOF; {
+		name := tokens[i]
+		if name.kind != tokIdent {
+			return nil, &SyntaxError{Pos: name.pos, Msg: "expected a field name"}
+		}
+		if _, ok := fields[name.text]; !ok {
+			return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("unknown field %q", name.text)}
+		}
+		if seen[name.text] {
+			return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("field %q is listed twice", name.text)}
+		}
+		seen[name.text] = true
+		field := OrderField{Field: name.text}
+		i++
+
+		if tok := tokens[i]; tok.kind == tokIdent {
+			switch strings.ToLower(tok.text) {
+			case "asc":
+			case "desc":
+				field.Desc = true
+			default:
+				return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("expected asc or desc, got %q", tok.text)}
+			}
+			i++
+		}
+		ordering = append(ordering, field)
+
+		switch tok := tokens[i]; tok.kind {
+		case tokEOF:
+		case tokComma:
+			i++
+			if tokens[i].kind == tokEOF {
+				return nil, &SyntaxError{Pos: tokens[i].pos, Msg: "expected a field name"}
+			}
+		default:
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+		}
+	}
+
+	if !seen["id"] {
+		ordering = append(ordering, OrderField{Field: "id"})
+	}
+	return ordering, nil
+}
+
+// Compare orders two books according to the ordering.
+func (o Ordering) Compare(a, b *bookiePb.Book) int {
+	for _, of := range o {
+		c := fields[of.Field].compare(a, b)
+		if of.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// String returns the canonical form of the ordering.
+func (o Ordering) String() string {
+	parts := make([]string, 0, len(o))
+	for _, of := range o {
+		if of.Desc {
+			parts = append(parts, of.Field+" desc")
+		} else {
+			parts = append(parts, of.Field)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
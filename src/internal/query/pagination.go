@@ -0,0 +1,105 @@
+// Package query implements listing semantics for books: filtering, ordering
+// and cursor-based pagination.
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded or
+// belongs to a different query.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageToken is the decoded form of an opaque page token. It records the last
+// book returned rather than an offset, so books inserted or removed before the
+// cursor do not shift later pages.
+type pageToken struct {
+	// Query is a fingerprint of the request the token was issued for.
+	Query string `json:"q"`
+	// Last holds the sort key of the last book on the previous page.
+	Last *bookKey `json:"l"`
+}
+
+// bookKey holds the fields of a book that listings are sorted on. Only the
+// fields of the ordering are set, which keeps tokens short.
+type bookKey struct {
+	ID          string `json:"id"`
+	Title       string `json:"t,omitempty"`
+	Description string `json:"d,omitempty"`
+	Author      string `json:"a,omitempty"`
+	Price       int64  `json:"p,omitempty"`
+}
+
+func keyOf(book *bookiePb.Book, order Ordering) *bookKey {
+	key := &bookKey{ID: book.GetId()}
+	for _, of := range order {
+		switch of.Field {
+		case "title":
+			key.Title = book.GetTitle()
+		case "description":
+			key.Description = book.GetDescription()
+		case "author":
+			key.Author = book.GetAuthor()
+		case "price":
+			key.Price = book.GetPrice()
+		}
+	}
+	return key
+}
+
+func (k *bookKey) book() *bookiePb.Book {
+	return &bookiePb.Book{
+		Id:          k.ID,
+		Title:       k.Title,
+		Description: k.Description,
+		Author:      k.Author,
+		Price:       k.Price,
+	}
+}
+
+// EncodePageToken returns an opaque token that resumes a listing after last.
+// queryID identifies the other listing parameters, such as the filter.
+func EncodePageToken(last *bookiePb.Book, order Ordering, queryID string) string {
+	return encodeToken(pageToken{Query: queryHash(queryID, order), Last: keyOf(last, order)})
+}
+
+// DecodePageToken returns the last book of the previous page, holding only
+// the fields of order, or nil for an empty token. A token issued for a
+// different query or ordering is rejected with ErrInvalidPageToken.
+func DecodePageToken(token string, order Ordering, queryID string) (*bookiePb.Book, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return decodeToken(token, queryHash(queryID, order))
+}
+
+// queryHash fingerprints the listing parameters a token is valid for.
+func queryHash(queryID string, order Ordering) string {
+	sum := sha256.Sum256([]byte(queryID + "\x00" + order.String()))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+func encodeToken(t pageToken) string {
+	raw, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeToken(token, queryID string) (*bookiePb.Book, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var t pageToken
+	if err := json.Unmarshal(raw, &t); err != nil || t.Last == nil {
+		return nil, ErrInvalidPageToken
+	}
+	if t.Query != queryID {
+		return nil, ErrInvalidPageToken
+	}
+	return t.Last.book(), nil
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+var byID = Ordering{{Field: "id"}}
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	order, err := ParseOrderBy("price desc, title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := &bookiePb.Book{Id: "b", Title: "Dune", Author: "Frank Herbert", Price: 900}
+
+	got, err := DecodePageToken(EncodePageToken(last, order, "q"), order, "q")
+	if err != nil {
+		t.Fatalf("DecodePageToken: %v", err)
+	}
+	// Only the sort key travels in the token.
+	want := &bookiePb.Book{Id: "b", Title: "Dune", Price: 900}
+	if !proto.Equal(got, want) {
+		t.Errorf("cursor = %v, want %v", got, want)
+	}
+	if order.Compare(got, last) != 0 {
+		t.Errorf("cursor does not sort like the book it was issued for")
+	}
+
+	if got, err := DecodePageToken("", order, "q"); got != nil || err != nil {
+		t.Errorf("empty token = %v, %v, want no cursor", got, err)
+	}
+}
+
+func TestPageTokenRejectsForeignTokens(t *testing.T) {
+	last := &bookiePb.Book{Id: "a"}
+	token := EncodePageToken(last, byID, "q1")
+	byTitle := Ordering{{Field: "title"}, {Field: "id"}}
+
+	tests := []struct {
+		name    string
+		token   string
+		order   Ordering
+		queryID string
+	}{
+		{"other query", token, byID, "q2"},
+		{"other ordering", token, byTitle, "q1"},
+		{"garbage", "not-a-token", byID, "q1"},
+	}
+	for _, tt := range tests {
+		if _, err := DecodePageToken(tt.token, tt.order, tt.queryID); !errors.Is(err, ErrInvalidPageToken) {
+			t.Errorf("%s: got %v, want ErrInvalidPageToken", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/query"
+)
+
+// SeedBooks returns the sample catalog the server starts with.
+func SeedBooks() []*bookiePb.Book {
+	return []*bookiePb.Book{
+		{
+			Id:          "1234",
+			Title:       "Harry Potter",
+			Price:       120,
+			Author:      "JK Rowling",
+			Description: "a lovely book",
+		},
+		{
+			Id:          "4567",
+			Title:       "Game of life",
+			Price:       450,
+			Author:      "Author Two",
+			Description: "This is a test",
+		},
+	}
+}
+
+// MemoryRepository is a BookRepository that keeps books in a slice. It is
+// safe for concurrent use; books are copied on the way in and out so callers
+// never share a message with the store.
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	books []*bookiePb.Book
+}
+
+// NewMemoryRepository creates a MemoryRepository holding the given books.
+func NewMemoryRepository(books []*bookiePb.Book) *MemoryRepository {
+	r := &MemoryRepository{books: make([]*bookiePb.Book, 0, len(books))}
+	for _, book := range books {
+		r.books = append(r.books, cloneBook(book))
+	}
+	return r
+}
+
+// Get returns the book with the given ID.
+func (r *MemoryRepository) Get(_ context.Context, id string) (*bookiePb.Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.indexOf(id); i >= 0 {
+		return cloneBook(r.books[i]), nil
+	}
+	return nil, ErrNotFound
+}
+
+// List returns all books.
+func (r *MemoryRepository) List(_ context.Context) ([]*bookiePb.Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	books := make([]*bookiePb.Book, 0, len(r.books))
+	for _, book := range r.books {
+		books = append(books, cloneBook(book))
+	}
+	return books, nil
+}
+
+// ListPage returns up to limit books that sort after the book after in order.
+func (r *MemoryRepository) ListPage(_ context.Context, order query.Ordering, after *bookiePb.Book, limit int) ([]*bookiePb.Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var page []*bookiePb.Book
+	for _, book := range r.books {
+		if after == nil || order.Compare(book, after) > 0 {
+			page = append(page, book)
+		}
+	}
+	slices.SortFunc(page, order.Compare)
+	page = page[:min(limit, len(page))]
+	for i, book := range page {
+		page[i] = cloneBook(book)
+	}
+	return page, nil
+}
+
+// Count returns the number of live and of soft-deleted books.
+func (r *MemoryRepository) Count(context.Context) (live, deleted int, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, book := range r.books {
+		if book.GetDeleteTime() != nil {
+			deleted++
+		} else {
+			live++
+		}
+	}
+	return live, deleted, nil
+}
+
+// Create appends the book to the store.
+func (r *MemoryRepository) Create(_ context.Context, book *bookiePb.Book) (*bookiePb.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(book.GetId()) >= 0 {
+		return nil, ErrAlreadyExists
+	}
+	r.books = append(r.books, cloneBook(book))
+	return book, nil
+}
+
+// CreateMany appends all books to the store, or none if any ID is taken.
+func (r *MemoryRepository) CreateMany(_ context.Context, books []*bookiePb.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool, len(books))
+	for _, book := range books {
+		if seen[book.GetId()] || r.indexOf(book.GetId()) >= 0 {
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, book.GetId())
+		}
+		seen[book.GetId()] = true
+	}
+	for _, book := range books {
+		r.books = append(r.books, cloneBook(book))
+	}
+	return nil
+}
+
+// Update copies the fields named by paths from patch to the live book with
+// the same ID.
+func (r *MemoryRepository) Update(_ context.Context, patch *bookiePb.Book, paths []string) (*bookiePb.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(patch.GetId())
+	if i < 0 || r.books[i].GetDeleteTime() != nil {
+		return nil, ErrNotFound
+	}
+	updated := cloneBook(r.books[i])
+	if err := setFields(updated, patch, paths); err != nil {
+		return nil, err
+	}
+	r.books[i] = updated
+	return cloneBook(updated), nil
+}
+
+// SoftDelete sets the delete_time of the live book with the given ID.
+func (r *MemoryRepository) SoftDelete(_ context.Context, id string, at time.Time) (*bookiePb.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 || r.books[i].GetDeleteTime() != nil {
+		return nil, ErrNotFound
+	}
+	r.books[i].DeleteTime = timestamppb.New(at)
+	return cloneBook(r.books[i]), nil
+}
+
+// Undelete clears the delete_time of the soft-deleted book with the given ID.
+func (r *MemoryRepository) Undelete(_ context.Context, id string) (*bookiePb.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	if r.books[i].GetDeleteTime() == nil {
+		return nil, ErrNotDeleted
+	}
+	r.books[i].DeleteTime = nil
+	return cloneBook(r.books[i]), nil
+}
+
+// Delete removes the book with the given ID.
+func (r *MemoryRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	r.books = append(r.books[:i], r.books[i+1:]...)
+	return nil
+}
+
+// Purge removes books soft-deleted before the given time.
+func (r *MemoryRepository) Purge(_ context.Context, deletedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.books[:0]
+	for _, book := range r.books {
+		if book.GetDeleteTime() == nil || !book.GetDeleteTime().AsTime().Before(deletedBefore) {
+			kept = append(kept, book)
+		}
+	}
+	purged := len(r.books) - len(kept)
+	clear(r.books[len(kept):])
+	r.books = kept
+	return purged, nil
+}
+
+// Ping always succeeds: memory is always available.
+func (r *MemoryRepository) Ping(context.Context) error {
+	return nil
+}
+
+// indexOf returns the position of the book with the given ID or -1.
+// Callers must hold r.mu.
+func (r *MemoryRepository) indexOf(id string) int {
+	for i, book := range r.books {
+		if book.GetId() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// setFields copies the fields named by paths from patch to book.
+func setFields(book, patch *bookiePb.Book, paths []string) error {
+	for _, path := range paths {
+		switch path {
+		case "title":
+			book.Title = patch.GetTitle()
+		case "description":
+			book.Description = patch.GetDescription()
+		case "author":
+			book.Author = patch.GetAuthor()
+		case "price":
+			book.Price = patch.GetPrice()
+		default:
+			return fmt.Errorf("unknown book field %q", path)
+		}
+	}
+	return nil
+}
+
+func cloneBook(book *bookiePb.Book) *bookiePb.Book {
+	return proto.Clone(book).(*bookiePb.Book)
+}
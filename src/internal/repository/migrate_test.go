@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %s has version %d, want %d", m.name, m.version, i+1)
+		}
+	}
+}
+
+func TestMigrateExistingDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "bookie.db")
+
+	repo, err := NewSQLiteRepository(ctx, path)
+	if err != nil {
+		t.Fatalf("first open: %v", err)
+	}
+	if _, err := repo.Create(ctx, &bookiePb.Book{Id: "1", Title: "Dune"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	for i := 0; i < 2; i++ {
+		if err := migrate(ctx, db); err != nil {
+			t.Fatalf("migrate run %d: %v", i+1, err)
+		}
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	var applied, version int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*), MAX(version) FROM schema_migrations`).Scan(&applied, &version); err != nil {
+		t.Fatalf("read schema_migrations: %v", err)
+	}
+	if applied != len(migrations) || version != migrations[len(migrations)-1].version {
+		t.Errorf("schema_migrations has %d rows up to version %d, want each of the %d migrations once",
+			applied, version, len(migrations))
+	}
+
+	var title string
+	if err := db.QueryRowContext(ctx, `SELECT title FROM books WHERE id = '1'`).Scan(&title); err != nil || title != "Dune" {
+		t.Errorf("book after re-running migrations = %q, %v; want Dune", title, err)
+	}
+}
@@ -0,0 +1,7 @@
+CREATE TABLE books (
+    id          TEXT PRIMARY KEY,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    author      TEXT NOT NULL DEFAULT '',
+    price       INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,4 @@
+-- Soft-delete tombstone, stored as Unix nanoseconds. NULL for live books.
+ALTER TABLE books ADD COLUMN delete_time INTEGER;
+
+CREATE INDEX books_delete_time ON books (delete_time) WHERE delete_time IS NOT NULL;
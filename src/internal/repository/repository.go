@@ -0,0 +1,60 @@
+// Package repository provides storage backends for the bookie gRPC server.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/query"
+)
+
+var (
+	// ErrNotFound is returned when a book with the requested ID does not exist.
+	ErrNotFound = errors.New("book not found")
+	// ErrAlreadyExists is returned when creating a book whose ID is already taken.
+	ErrAlreadyExists = errors.New("book already exists")
+	// ErrNotDeleted is returned when restoring a book that is not soft-deleted.
+	ErrNotDeleted = errors.New("book is not deleted")
+)
+
+// BookRepository is the storage abstraction used by the bookie service.
+// Soft-deleted books are ordinary books with a delete_time set; only Delete
+// and Purge remove rows for good. Update, SoftDelete and Undelete check the
+// state of the book and change it in one atomic write, so concurrent writers
+// cannot overwrite each other's changes.
+type BookRepository interface {
+	// Get returns the book with the given ID or ErrNotFound.
+	Get(ctx context.Context, id string) (*bookiePb.Book, error)
+	// List returns every stored book, including soft-deleted ones.
+	List(ctx context.Context) ([]*bookiePb.Book, error)
+	// ListPage returns up to limit books, including soft-deleted ones, that
+	// sort after the book after in order, or the first books when after is
+	// nil. order must be a total order, as returned by query.ParseOrderBy.
+	ListPage(ctx context.Context, order query.Ordering, after *bookiePb.Book, limit int) ([]*bookiePb.Book, error)
+	// Count returns the number of live and of soft-deleted books.
+	Count(ctx context.Context) (live, deleted int, err error)
+	// Create stores a new book and returns it, or ErrAlreadyExists if the ID is taken.
+	Create(ctx context.Context, book *bookiePb.Book) (*bookiePb.Book, error)
+	// CreateMany stores all books or none of them. It returns ErrAlreadyExists,
+	// wrapped with the offending ID, if any ID is taken or repeated.
+	CreateMany(ctx context.Context, books []*bookiePb.Book) error
+	// Update copies the fields named by paths from patch to the live book with
+	// the same ID and returns the result. It returns ErrNotFound if there is
+	// no such book or it is soft-deleted.
+	Update(ctx context.Context, patch *bookiePb.Book, paths []string) (*bookiePb.Book, error)
+	// SoftDelete sets the delete_time of the live book with the given ID and
+	// returns it, or returns ErrNotFound if there is no such live book.
+	SoftDelete(ctx context.Context, id string, at time.Time) (*bookiePb.Book, error)
+	// Undelete clears the delete_time of the book with the given ID and
+	// returns it. It returns ErrNotFound if the book does not exist and
+	// ErrNotDeleted if it is live.
+	Undelete(ctx context.Context, id string) (*bookiePb.Book, error)
+	// Delete removes the book with the given ID or returns ErrNotFound.
+	Delete(ctx context.Context, id string) error
+	// Purge removes books soft-deleted before the given time and reports how many were removed.
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+	// Ping reports whether the storage can serve requests.
+	Ping(ctx context.Context) error
+}
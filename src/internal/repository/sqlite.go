@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	// Pure-Go SQLite driver, keeps the binaries buildable with CGO_ENABLED=0.
+	_ "modernc.org/sqlite"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/query"
+)
+
+const bookColumns = `id, title, description, author, price, delete_time`
+
+// SQLiteRepository is a BookRepository persisted in a SQLite database file.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository opens the database at path, creating it if needed,
+// and brings the schema up to date before returning.
+func NewSQLiteRepository(ctx context.Context, path string) (*SQLiteRepository, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("connect to sqlite database: %w", err)
+	}
+	if err := migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &SQLiteRepository{db: db}, nil
+}
+
+// sqliteDSN builds the file: URI of the database at path. The path is escaped
+// so that characters such as '?', '#' and '%' stay part of the file name.
+func sqliteDSN(path string) string {
+	pragmas := url.Values{"_pragma": {"busy_timeout(5000)", "journal_mode(WAL)", "foreign_keys(1)"}}
+	dsn := url.URL{Scheme: "file", Opaque: (&url.URL{Path: path}).EscapedPath(), RawQuery: pragmas.Encode()}
+	return dsn.String()
+}
+
+// Close closes the underlying database.
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// Ping checks that the database can still be reached.
+func (r *SQLiteRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Get returns the book with the given ID.
+func (r *SQLiteRepository) Get(ctx context.Context, id string) (*bookiePb.Book, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+bookColumns+` FROM books WHERE id = ?`, id)
+	book, err := scanBook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return book, err
+}
+
+// List returns all books in insertion order.
+func (r *SQLiteRepository) List(ctx context.Context) ([]*bookiePb.Book, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+bookColumns+` FROM books ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var books []*bookiePb.Book
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	return books, rows.Err()
+}
+
+// ListPage returns up to limit books that sort after the book after in order.
+// The ordering is translated to SQL, so only one page is read at a time.
+func (r *SQLiteRepository) ListPage(ctx context.Context, order query.Ordering, after *bookiePb.Book, limit int) ([]*bookiePb.Book, error) {
+	// Column names come from sortValue, which only accepts known fields.
+	orderBy := make([]string, 0, len(order))
+	for _, of := range order {
+		if _, err := sortValue(&bookiePb.Book{}, of.Field); err != nil {
+			return nil, err
+		}
+		if of.Desc {
+			orderBy = append(orderBy, of.Field+" DESC")
+		} else {
+			orderBy = append(orderBy, of.Field)
+		}
+	}
+
+	where, args := "", []any{}
+	if after != nil {
+		// Keyset condition: (f1 > v1) OR (f1 = v1 AND f2 > v2) OR ...
+		// sortValue cannot fail here: every field was checked above.
+		alternatives := make([]string, 0, len(order))
+		for i, of := range order {
+			terms := make([]string, 0, i+1)
+			for _, prev := range order[:i] {
+				value, _ := sortValue(after, prev.Field)
+				terms = append(terms, prev.Field+" = ?")
+				args = append(args, value)
+			}
+			value, _ := sortValue(after, of.Field)
+			if of.Desc {
+				terms = append(terms, of.Field+" < ?")
+			} else {
+				terms = append(terms, of.Field+" > ?")
+			}
+			args = append(args, value)
+			alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		}
+		where = ` WHERE ` + strings.Join(alternatives, " OR ")
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+bookColumns+` FROM books`+where+` ORDER BY `+strings.Join(orderBy, ", ")+` LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var books []*bookiePb.Book
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	return books, rows.Err()
+}
+
+// Count returns the number of live and of soft-deleted books without
+// reading them.
+func (r *SQLiteRepository) Count(ctx context.Context) (live, deleted int, err error) {
+	err = r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) - COUNT(delete_time), COUNT(delete_time) FROM books`,
+	).Scan(&live, &deleted)
+	return live, deleted, err
+}
+
+// Create inserts a new book.
+func (r *SQLiteRepository) Create(ctx context.Context, book *bookiePb.Book) (*bookiePb.Book, error) {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO books (`+bookColumns+`) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		book.GetId(), book.GetTitle(), book.GetDescription(), book.GetAuthor(), book.GetPrice(), deleteTimeValue(book),
+	)
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrAlreadyExists
+	}
+	return book, nil
+}
+
+// CreateMany inserts all books in a single transaction.
+func (r *SQLiteRepository) CreateMany(ctx context.Context, books []*bookiePb.Book) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO books (`+bookColumns+`) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, book := range books {
+		res, err := stmt.ExecContext(ctx,
+			book.GetId(), book.GetTitle(), book.GetDescription(), book.GetAuthor(), book.GetPrice(), deleteTimeValue(book),
+		)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, book.GetId())
+		}
+	}
+	return tx.Commit()
+}
+
+// Update copies the fields named by paths from patch to the live book with
+// the same ID in a single conditional UPDATE.
+func (r *SQLiteRepository) Update(ctx context.Context, patch *bookiePb.Book, paths []string) (*bookiePb.Book, error) {
+	// Column names come from fieldValue, which only accepts known fields.
+	// "id = id" keeps the statement valid when paths is empty.
+	sets := []string{"id = id"}
+	args := make([]any, 0, len(paths)+1)
+	for _, path := range paths {
+		value, err := fieldValue(patch, path)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, path+" = ?")
+		args = append(args, value)
+	}
+	args = append(args, patch.GetId())
+
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE books SET `+strings.Join(sets, ", ")+` WHERE id = ? AND delete_time IS NULL RETURNING `+bookColumns,
+		args...,
+	)
+	book, err := scanBook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return book, err
+}
+
+// SoftDelete sets the delete_time of the live book with the given ID.
+func (r *SQLiteRepository) SoftDelete(ctx context.Context, id string, at time.Time) (*bookiePb.Book, error) {
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE books SET delete_time = ? WHERE id = ? AND delete_time IS NULL RETURNING `+bookColumns,
+		at.UnixNano(), id,
+	)
+	book, err := scanBook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return book, err
+}
+
+// Undelete clears the delete_time of the soft-deleted book with the given ID.
+func (r *SQLiteRepository) Undelete(ctx context.Context, id string) (*bookiePb.Book, error) {
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE books SET delete_time = NULL WHERE id = ? AND delete_time IS NOT NULL RETURNING `+bookColumns,
+		id,
+	)
+	book, err := scanBook(row)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return book, err
+	}
+
+	// Nothing changed; find out why. This read only picks the error returned.
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM books WHERE id = ?)`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrNotDeleted
+	}
+	return nil, ErrNotFound
+}
+
+// Delete removes the book with the given ID.
+func (r *SQLiteRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM books WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Purge removes books soft-deleted before the given time.
+func (r *SQLiteRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM books WHERE delete_time IS NOT NULL AND delete_time < ?`,
+		deletedBefore.UnixNano(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// scanner is satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBook(s scanner) (*bookiePb.Book, error) {
+	book := &bookiePb.Book{}
+	var deleteTime sql.NullInt64
+	if err := s.Scan(&book.Id, &book.Title, &book.Description, &book.Author, &book.Price, &deleteTime); err != nil {
+		return nil, err
+	}
+	if deleteTime.Valid {
+		book.DeleteTime = timestamppb.New(time.Unix(0, deleteTime.Int64))
+	}
+	return book, nil
+}
+
+// fieldValue returns the value of the updatable field named path, which is
+// also the name of its column.
+func fieldValue(book *bookiePb.Book, path string) (any, error) {
+	switch path {
+	case "title":
+		return book.GetTitle(), nil
+	case "description":
+		return book.GetDescription(), nil
+	case "author":
+		return book.GetAuthor(), nil
+	case "price":
+		return book.GetPrice(), nil
+	default:
+		return nil, fmt.Errorf("unknown book field %q", path)
+	}
+}
+
+// sortValue is like fieldValue but also accepts the id, which listings can
+// be ordered by but updates cannot change.
+func sortValue(book *bookiePb.Book, path string) (any, error) {
+	if path == "id" {
+		return book.GetId(), nil
+	}
+	return fieldValue(book, path)
+}
+
+// deleteTimeValue returns the delete_time column value for book.
+func deleteTimeValue(book *bookiePb.Book) sql.NullInt64 {
+	if book.GetDeleteTime() == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: book.GetDeleteTime().AsTime().UnixNano(), Valid: true}
+}
+
+// expectAffected converts a statement that matched no rows into ErrNotFound.
+func expectAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
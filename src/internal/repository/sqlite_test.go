@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/query"
+)
+
+// openSQLite opens a fresh database at path and closes it when the test ends.
+func openSQLite(t *testing.T, path string) *SQLiteRepository {
+	t.Helper()
+	repo, err := NewSQLiteRepository(context.Background(), path)
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func newTestSQLite(t *testing.T) *SQLiteRepository {
+	t.Helper()
+	return openSQLite(t, filepath.Join(t.TempDir(), "bookie.db"))
+}
+
+func TestSQLiteCRUD(t *testing.T) {
+	repo := newTestSQLite(t)
+	ctx := context.Background()
+	book := &bookiePb.Book{Id: "1", Title: "Dune", Author: "Frank Herbert", Description: "spice", Price: 900}
+
+	if _, err := repo.Create(ctx, book); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.Create(ctx, book); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("second Create = %v, want ErrAlreadyExists", err)
+	}
+
+	got, err := repo.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !proto.Equal(got, book) {
+		t.Errorf("Get = %v, want %v", got, book)
+	}
+
+	updated, err := repo.Update(ctx, &bookiePb.Book{Id: "1", Title: "ignored", Price: 1000}, []string{"price"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.GetTitle() != "Dune" || updated.GetPrice() != 1000 {
+		t.Errorf("Update = %v, want only the price changed", updated)
+	}
+	if _, err := repo.Update(ctx, &bookiePb.Book{Id: "missing"}, []string{"price"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update of a missing book = %v, want ErrNotFound", err)
+	}
+
+	books, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(books) != 1 || books[0].GetPrice() != 1000 {
+		t.Errorf("List = %v, want the updated book", books)
+	}
+
+	if err := repo.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Delete(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.Get(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSQLiteDeleteTimeRoundTrip(t *testing.T) {
+	repo := newTestSQLite(t)
+	ctx := context.Background()
+	deleted := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+
+	if _, err := repo.Create(ctx, &bookiePb.Book{Id: "1", Title: "Dune", DeleteTime: timestamppb.New(deleted)}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := repo.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !got.GetDeleteTime().AsTime().Equal(deleted) {
+		t.Errorf("delete_time = %v, want %v", got.GetDeleteTime().AsTime(), deleted)
+	}
+
+	if _, err := repo.Undelete(ctx, "1"); err != nil {
+		t.Fatalf("Undelete: %v", err)
+	}
+	if got, _ := repo.Get(ctx, "1"); got.GetDeleteTime() != nil {
+		t.Errorf("delete_time = %v after clearing it, want nil", got.GetDeleteTime())
+	}
+}
+
+func TestSQLiteConditionalWrites(t *testing.T) {
+	repo := newTestSQLite(t)
+	ctx := context.Background()
+	if _, err := repo.Create(ctx, &bookiePb.Book{Id: "1", Title: "Dune"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := repo.Undelete(ctx, "1"); !errors.Is(err, ErrNotDeleted) {
+		t.Errorf("Undelete of a live book = %v, want ErrNotDeleted", err)
+	}
+	if _, err := repo.Undelete(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Undelete of a missing book = %v, want ErrNotFound", err)
+	}
+
+	at := time.Now()
+	deleted, err := repo.SoftDelete(ctx, "1", at)
+	if err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+	if !deleted.GetDeleteTime().AsTime().Equal(at) {
+		t.Errorf("delete_time = %v, want %v", deleted.GetDeleteTime().AsTime(), at)
+	}
+	if _, err := repo.SoftDelete(ctx, "1", time.Now()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second SoftDelete = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.Update(ctx, &bookiePb.Book{Id: "1", Title: "Dune Messiah"}, []string{"title"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update of a deleted book = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.Update(ctx, &bookiePb.Book{Id: "1"}, []string{"id"}); err == nil {
+		t.Error("Update of the id field succeeded, want an error")
+	}
+
+	restored, err := repo.Undelete(ctx, "1")
+	if err != nil {
+		t.Fatalf("Undelete: %v", err)
+	}
+	if restored.GetDeleteTime() != nil || restored.GetTitle() != "Dune" {
+		t.Errorf("Undelete = %v, want the live, unchanged book", restored)
+	}
+}
+
+func TestSQLiteCreateManyRollsBack(t *testing.T) {
+	repo := newTestSQLite(t)
+	ctx := context.Background()
+	if _, err := repo.Create(ctx, &bookiePb.Book{Id: "taken", Title: "Existing"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	tests := map[string][]*bookiePb.Book{
+		"existing id": {{Id: "a", Title: "A"}, {Id: "taken", Title: "B"}, {Id: "c", Title: "C"}},
+		"repeated id": {{Id: "a", Title: "A"}, {Id: "a", Title: "A again"}},
+	}
+	for name, books := range tests {
+		if err := repo.CreateMany(ctx, books); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("%s: CreateMany = %v, want ErrAlreadyExists", name, err)
+		}
+		stored, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(stored) != 1 {
+			t.Errorf("%s: %d books stored, want the batch rolled back", name, len(stored))
+		}
+	}
+
+	if err := repo.CreateMany(ctx, []*bookiePb.Book{{Id: "a", Title: "A"}, {Id: "b", Title: "B"}}); err != nil {
+		t.Fatalf("CreateMany: %v", err)
+	}
+	if stored, _ := repo.List(ctx); len(stored) != 3 {
+		t.Errorf("%d books stored, want 3", len(stored))
+	}
+}
+
+func TestSQLitePurge(t *testing.T) {
+	repo := newTestSQLite(t)
+	ctx := context.Background()
+	cutoff := time.Now()
+	books := []*bookiePb.Book{
+		{Id: "live", Title: "Live"},
+		{Id: "old", Title: "Old", DeleteTime: timestamppb.New(cutoff.Add(-time.Hour))},
+		{Id: "recent", Title: "Recent", DeleteTime: timestamppb.New(cutoff.Add(time.Minute))},
+	}
+	if err := repo.CreateMany(ctx, books); err != nil {
+		t.Fatalf("CreateMany: %v", err)
+	}
+
+	if live, deleted, err := repo.Count(ctx); err != nil || live != 1 || deleted != 2 {
+		t.Errorf("Count = %d live, %d deleted, %v; want 1 and 2", live, deleted, err)
+	}
+
+	n, err := repo.Purge(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Purge removed %d books, want 1", n)
+	}
+	if _, err := repo.Get(ctx, "old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(old) = %v, want ErrNotFound", err)
+	}
+	for _, id := range []string{"live", "recent"} {
+		if _, err := repo.Get(ctx, id); err != nil {
+			t.Errorf("Get(%s) = %v, want the book kept", id, err)
+		}
+	}
+	if live, deleted, err := repo.Count(ctx); err != nil || live != 1 || deleted != 1 {
+		t.Errorf("Count after Purge = %d live, %d deleted, %v; want 1 and 1", live, deleted, err)
+	}
+}
+
+func TestSQLitePathNeedsEscaping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books?v=1#a%20b.db")
+	repo := openSQLite(t, path)
+	if _, err := repo.Create(context.Background(), &bookiePb.Book{Id: "1", Title: "Dune"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("database file was not created at %q: %v", path, err)
+	}
+}
+
+func TestSQLiteListPageMatchesMemory(t *testing.T) {
+	books := []*bookiePb.Book{
+		{Id: "a", Title: "Dune", Author: "Herbert", Price: 900},
+		{Id: "b", Title: "Emma", Author: "Austen", Price: 300},
+		{Id: "c", Title: "Dune", Author: "Herbert", Price: 300},
+		{Id: "d", Title: "Ulysses", Author: "Joyce", Price: 900, DeleteTime: timestamppb.Now()},
+		{Id: "e", Title: "Persuasion", Author: "Austen", Price: 500},
+	}
+	sqlite := newTestSQLite(t)
+	ctx := context.Background()
+	if err := sqlite.CreateMany(ctx, books); err != nil {
+		t.Fatalf("CreateMany: %v", err)
+	}
+	memory := NewMemoryRepository(books)
+
+	for _, orderBy := range []string{"", "price desc, title", "author, price desc", "title desc"} {
+		order, err := query.ParseOrderBy(orderBy)
+		if err != nil {
+			t.Fatalf("ParseOrderBy(%q): %v", orderBy, err)
+		}
+		want := slices.Clone(books)
+		slices.SortFunc(want, order.Compare)
+
+		for name, repo := range map[string]BookRepository{"memory": memory, "sqlite": sqlite} {
+			var got []string
+			var after *bookiePb.Book
+			for {
+				page, err := repo.ListPage(ctx, order, after, 2)
+				if err != nil {
+					t.Fatalf("%s: ListPage(%q): %v", name, orderBy, err)
+				}
+				for _, book := range page {
+					got = append(got, book.GetId())
+				}
+				if len(page) < 2 {
+					break
+				}
+				after = page[len(page)-1]
+			}
+			if wantIDs := bookIDs(want); !slices.Equal(got, wantIDs) {
+				t.Errorf("%s: order_by %q paged as %v, want %v", name, orderBy, got, wantIDs)
+			}
+		}
+	}
+}
+
+func bookIDs(books []*bookiePb.Book) []string {
+	ids := make([]string, 0, len(books))
+	for _, book := range books {
+		ids = append(ids, book.GetId())
+	}
+	return ids
+}
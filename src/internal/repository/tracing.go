@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/query"
+)
+
+const tracerName = "github.com/sadhakbj/bookie-grpc/src/internal/repository"
+
+// tracedRepository records a span for every call to the wrapped repository.
+type tracedRepository struct {
+	repo   BookRepository
+	system string
+	tracer trace.Tracer
+}
+
+// NewTracedRepository wraps repo so calls made within a trace run in a child
+// span of the caller's span. Calls outside any trace, such as metric scrapes
+// and background purges, are not traced. system names the backend, e.g.
+// "sqlite", and is recorded as the db.system.name attribute.
+func NewTracedRepository(repo BookRepository, system string) BookRepository {
+	return &tracedRepository{repo: repo, system: system, tracer: otel.Tracer(tracerName)}
+}
+
+// start opens the span of operation when ctx is part of a trace. ErrNotFound
+// and ErrNotDeleted are answers rather than failures, so end leaves the span
+// status unset for them.
+func (r *tracedRepository) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, func(error) {}
+	}
+	attrs = append(attrs,
+		attribute.String("db.system.name", r.system),
+		attribute.String("db.operation.name", operation),
+	)
+	ctx, span := r.tracer.Start(ctx, "BookRepository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, func(err error) {
+		if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrNotDeleted) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (r *tracedRepository) Get(ctx context.Context, id string) (*bookiePb.Book, error) {
+	ctx, end := r.start(ctx, "Get", attribute.String("book.id", id))
+	book, err := r.repo.Get(ctx, id)
+	end(err)
+	return book, err
+}
+
+func (r *tracedRepository) List(ctx context.Context) ([]*bookiePb.Book, error) {
+	ctx, end := r.start(ctx, "List")
+	books, err := r.repo.List(ctx)
+	end(err)
+	return books, err
+}
+
+func (r *tracedRepository) ListPage(ctx context.Context, order query.Ordering, after *bookiePb.Book, limit int) ([]*bookiePb.Book, error) {
+	ctx, end := r.start(ctx, "ListPage", attribute.String("order_by", order.String()), attribute.Int("limit", limit))
+	books, err := r.repo.ListPage(ctx, order, after, limit)
+	end(err)
+	return books, err
+}
+
+func (r *tracedRepository) Count(ctx context.Context) (int, int, error) {
+	ctx, end := r.start(ctx, "Count")
+	live, deleted, err := r.repo.Count(ctx)
+	end(err)
+	return live, deleted, err
+}
+
+func (r *tracedRepository) Create(ctx context.Context, book *bookiePb.Book) (*bookiePb.Book, error) {
+	ctx, end := r.start(ctx, "Create", attribute.String("book.id", book.GetId()))
+	created, err := r.repo.Create(ctx, book)
+	end(err)
+	return created, err
+}
+
+func (r *tracedRepository) CreateMany(ctx context.Context, books []*bookiePb.Book) error {
+	ctx, end := r.start(ctx, "CreateMany", attribute.Int("book.count", len(books)))
+	err := r.repo.CreateMany(ctx, books)
+	end(err)
+	return err
+}
+
+func (r *tracedRepository) Update(ctx context.Context, patch *bookiePb.Book, paths []string) (*bookiePb.Book, error) {
+	ctx, end := r.start(ctx, "Update", attribute.String("book.id", patch.GetId()))
+	updated, err := r.repo.Update(ctx, patch, paths)
+	end(err)
+	return updated, err
+}
+
+func (r *tracedRepository) SoftDelete(ctx context.Context, id string, at time.Time) (*bookiePb.Book, error) {
+	ctx, end := r.start(ctx, "SoftDelete", attribute.String("book.id", id))
+	deleted, err := r.repo.SoftDelete(ctx, id, at)
+	end(err)
+	return deleted, err
+}
+
+func (r *tracedRepository) Undelete(ctx context.Context, id string) (*bookiePb.Book, error) {
+	ctx, end := r.start(ctx, "Undelete", attribute.String("book.id", id))
+	restored, err := r.repo.Undelete(ctx, id)
+	end(err)
+	return restored, err
+}
+
+func (r *tracedRepository) Delete(ctx context.Context, id string) error {
+	ctx, end := r.start(ctx, "Delete", attribute.String("book.id", id))
+	err := r.repo.Delete(ctx, id)
+	end(err)
+	return err
+}
+
+func (r *tracedRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	ctx, end := r.start(ctx, "Purge", attribute.String("deleted_before", deletedBefore.UTC().Format(time.RFC3339)))
+	n, err := r.repo.Purge(ctx, deletedBefore)
+	end(err)
+	return n, err
+}
+
+func (r *tracedRepository) Ping(ctx context.Context) error {
+	ctx, end := r.start(ctx, "Ping")
+	err := r.repo.Ping(ctx)
+	end(err)
+	return err
+}
@@ -0,0 +1,91 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+// UnaryClientInterceptor forwards the request id of the call context as
+// outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+func outgoing(ctx context.Context) context.Context {
+	if id := FromContext(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+	}
+	return ctx
+}
+
+// UnaryServerInterceptor takes the request id from incoming metadata, or
+// generates one, and stores it in the context together with a logger that
+// adds it to every line. Each call is logged once it completes.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = incoming(ctx)
+		start := time.Now()
+		res, err := handler(ctx, req)
+		logCall(ctx, info.FullMethod, start, err)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := incoming(ss.Context())
+		start := time.Now()
+		err := handler(srv, utils.ServerStreamWithContext(ctx, ss))
+		logCall(ctx, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func incoming(ctx context.Context) context.Context {
+	var id string
+	if values := metadata.ValueFromIncomingContext(ctx, MetadataKey); len(values) > 0 && Valid(values[0]) {
+		id = values[0]
+	} else {
+		id = New()
+	}
+	ctx = NewContext(ctx, id)
+	return utils.ContextWithLogger(ctx, utils.LoggerFromContext(ctx).With(Attr(id)))
+}
+
+// healthMethodPrefix starts the methods of the gRPC health service, which
+// orchestrators and the HTTP client probe every few seconds.
+var healthMethodPrefix = "/" + healthpb.Health_ServiceDesc.ServiceName + "/"
+
+// logCall logs a completed call. Successful health probes are logged at Debug
+// so they do not drown out the requests of actual users.
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	logger := utils.LoggerFromContext(ctx)
+	switch {
+	case code == codes.Unknown, code == codes.Internal, code == codes.DataLoss, code == codes.Unavailable:
+		logger.Error("gRPC request", "method", method, "code", code.String(), "duration", time.Since(start), "error", err)
+	case strings.HasPrefix(method, healthMethodPrefix):
+		logger.Debug("gRPC request", "method", method, "code", code.String(), "duration", time.Since(start))
+	default:
+		logger.Info("gRPC request", "method", method, "code", code.String(), "duration", time.Since(start))
+	}
+}
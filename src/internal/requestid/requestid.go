@@ -0,0 +1,59 @@
+// Package requestid carries a request id from the HTTP client (BFF) to the
+// gRPC server so log lines on both sides can be correlated.
+package requestid
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header is the HTTP header that carries the request id.
+	Header = "X-Request-ID"
+	// MetadataKey is the gRPC metadata key that carries the request id.
+	MetadataKey = "x-request-id"
+	// LogKey is the attribute name of the request id in log lines.
+	LogKey = "request_id"
+	// maxLength bounds ids accepted from callers.
+	maxLength = 128
+)
+
+type contextKey struct{}
+
+// New returns a fresh random request id.
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether an id received from a caller can be used as is. Ids
+// end up in logs and headers, so only short printable ASCII without spaces
+// is accepted.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Attr returns the log attribute for id.
+func Attr(id string) slog.Attr {
+	return slog.String(LogKey, id)
+}
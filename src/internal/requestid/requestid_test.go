@@ -0,0 +1,158 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/utils"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"7f1c2a4e-9b1d-4c53-8a3e-2f6d1b0c9e11", true},
+		{"req-42", true},
+		{"", false},
+		{"has space", false},
+		{"new\nline", false},
+		{"café", false},
+		{strings.Repeat("a", maxLength), true},
+		{strings.Repeat("a", maxLength+1), false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+// recordingServer remembers the request id of the last GetByID call.
+type recordingServer struct {
+	bookiePb.UnimplementedBookieServer
+	id string
+}
+
+func (s *recordingServer) GetByID(ctx context.Context, _ *bookiePb.GetByIDRequest) (*bookiePb.GetByIDResponse, error) {
+	s.id = FromContext(ctx)
+	return &bookiePb.GetByIDResponse{}, nil
+}
+
+func TestPropagation(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	svc := &recordingServer{}
+	bookiePb.RegisterBookieServer(server, svc)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := bookiePb.NewBookieClient(conn)
+
+	tests := []struct {
+		name   string
+		id     string
+		wantID string // "" accepts any generated id
+	}{
+		{name: "forwards id", id: "req-42", wantID: "req-42"},
+		{name: "generates missing id"},
+		{name: "replaces invalid id", id: "bad id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			ctx := context.Background()
+			if tt.id != "" {
+				ctx = NewContext(ctx, tt.id)
+			}
+			if _, err := client.GetByID(ctx, &bookiePb.GetByIDRequest{Id: "1"}); err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+
+			if tt.wantID != "" && svc.id != tt.wantID {
+				t.Errorf("server saw id %q, want %q", svc.id, tt.wantID)
+			}
+			if !Valid(svc.id) || (tt.wantID == "" && svc.id == tt.id) {
+				t.Errorf("server saw id %q, want a generated one", svc.id)
+			}
+
+			// The call is logged with the id the handler saw.
+			var entry struct {
+				Method    string `json:"method"`
+				RequestID string `json:"request_id"`
+			}
+			if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+				t.Fatalf("decode log %q: %v", logs.String(), err)
+			}
+			if entry.RequestID != svc.id || entry.Method != bookiePb.Bookie_GetByID_FullMethodName {
+				t.Errorf("logged %+v, want request_id %q", entry, svc.id)
+			}
+		})
+	}
+}
+
+func TestLogCallLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		err       error
+		wantLevel string // "" means nothing is logged at Info
+	}{
+		{name: "book call", method: bookiePb.Bookie_GetByID_FullMethodName, wantLevel: "INFO"},
+		{name: "health probe", method: healthpb.Health_Check_FullMethodName},
+		{name: "failed health probe", method: healthpb.Health_Check_FullMethodName, err: status.Error(codes.Unavailable, "down"), wantLevel: "ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			ctx := utils.ContextWithLogger(context.Background(), slog.New(slog.NewJSONHandler(&logs, nil)))
+			logCall(ctx, tt.method, time.Now(), tt.err)
+
+			if tt.wantLevel == "" {
+				if logs.Len() > 0 {
+					t.Errorf("logged %s, want nothing above Debug", logs.String())
+				}
+				return
+			}
+			var entry struct {
+				Level string `json:"level"`
+			}
+			if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+				t.Fatalf("decode log %q: %v", logs.String(), err)
+			}
+			if entry.Level != tt.wantLevel {
+				t.Errorf("level = %s, want %s", entry.Level, tt.wantLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,443 @@
+// Package bookstest provides an in-memory books.BookService for tests.
+package bookstest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/services/books"
+	"github.com/sadhakbj/bookie-grpc/src/internal/validation"
+)
+
+// Fake is an in-memory books.BookService. It returns the same gRPC statuses as
+// the real server for missing books, duplicate ids, invalid input and done
+// contexts, but ignores ListOptions.Filter and OrderBy: books are returned in
+// insertion order. Set Err to make every call fail, e.g. with
+// codes.Unavailable to simulate a transport failure. Err and Latency must be
+// set before the Fake is used.
+type Fake struct {
+	// Err, when set, is returned by every method before anything else happens.
+	Err error
+	// Latency delays every call like a slow server. A call whose context ends
+	// first fails with the matching status, e.g. DeadlineExceeded.
+	Latency time.Duration
+
+	mu      sync.Mutex
+	books   map[string]*books.Book
+	order   []string
+	events  []*books.BookEvent
+	nextID  int
+	changed chan struct{}
+}
+
+var _ books.BookService = (*Fake)(nil)
+
+// NewFake returns a Fake holding copies of the given books.
+func NewFake(initial ...*books.Book) *Fake {
+	f := &Fake{books: map[string]*books.Book{}, changed: make(chan struct{})}
+	for _, book := range initial {
+		f.put(book)
+	}
+	return f
+}
+
+// Book returns a copy of the stored book with the given id, deleted or not.
+func (f *Fake) Book(id string) (*books.Book, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	book, ok := f.books[id]
+	if !ok {
+		return nil, false
+	}
+	return clone(book), true
+}
+
+// GetBooks returns a page of books. Page tokens are offsets into the listing.
+func (f *Fake) GetBooks(ctx context.Context, opts books.ListOptions) (*books.BookPage, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	matching := f.list(opts.ShowDeleted)
+	offset := 0
+	if opts.PageToken != "" {
+		n, err := strconv.Atoi(opts.PageToken)
+		if err != nil || n < 0 || n > len(matching) {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid page_token")
+		}
+		offset = n
+	}
+	size := opts.PageSize
+	if size <= 0 {
+		size = 25
+	}
+
+	end := min(offset+size, len(matching))
+	page := &books.BookPage{Books: matching[offset:end], TotalSize: len(matching), Revision: int64(len(f.events))}
+	if end < len(matching) {
+		page.NextPageToken = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+// GetByID returns the live book with the given id.
+func (f *Fake) GetByID(ctx context.Context, id string) (*books.Book, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	book, err := f.live(id)
+	if err != nil {
+		return nil, err
+	}
+	return clone(book), nil
+}
+
+// CreateBook validates and stores a book, generating an id when it has none.
+func (f *Fake) CreateBook(ctx context.Context, input *books.BookInput) (*books.Book, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	if err := validation.Check(createRequest(input)); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.books[input.ID]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "Book with ID %s already exists", input.ID)
+	}
+	book := f.create(input)
+	return clone(book), nil
+}
+
+// UpdateBook changes the non-nil fields of update on a live book.
+func (f *Fake) UpdateBook(ctx context.Context, id string, update *books.BookUpdate) (*books.Book, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	book, err := f.live(id)
+	if err != nil {
+		return nil, err
+	}
+	if update.Title != nil {
+		if *update.Title == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid UpdateBookRequest: book.title is required")
+		}
+		book.Title = *update.Title
+	}
+	if update.Description != nil {
+		book.Description = *update.Description
+	}
+	if update.Author != nil {
+		book.Author = *update.Author
+	}
+	if update.Price != nil {
+		book.Price = *update.Price
+	}
+	f.record("updated", book)
+	return clone(book), nil
+}
+
+// DeleteBook soft-deletes a live book.
+func (f *Fake) DeleteBook(ctx context.Context, id string) (*books.Book, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	book, err := f.live(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	book.DeleteTime = &now
+	f.record("deleted", book)
+	return clone(book), nil
+}
+
+// UndeleteBook restores a soft-deleted book.
+func (f *Fake) UndeleteBook(ctx context.Context, id string) (*books.Book, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	book, ok := f.books[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Book with ID %s not found", id)
+	}
+	if book.DeleteTime == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Book with ID %s is not deleted", id)
+	}
+	book.DeleteTime = nil
+	f.record("updated", book)
+	return clone(book), nil
+}
+
+// StreamBooks iterates over a snapshot of the books.
+func (f *Fake) StreamBooks(ctx context.Context, opts books.StreamOptions) iter.Seq2[*books.Book, error] {
+	return func(yield func(*books.Book, error) bool) {
+		if err := f.callErr(ctx); err != nil {
+			yield(nil, err)
+			return
+		}
+		f.mu.Lock()
+		snapshot := f.list(opts.ShowDeleted)
+		f.mu.Unlock()
+
+		for _, book := range snapshot {
+			if !yield(book, nil) {
+				return
+			}
+		}
+	}
+}
+
+// WatchBooks replays the recorded changes after a positive afterRevision,
+// then waits for new ones until ctx is cancelled, which is yielded as a
+// Canceled status. Zero only sends changes made after the iteration starts,
+// like the server.
+func (f *Fake) WatchBooks(ctx context.Context, afterRevision int64) iter.Seq2[*books.BookEvent, error] {
+	return func(yield func(*books.BookEvent, error) bool) {
+		if err := f.callErr(ctx); err != nil {
+			yield(nil, err)
+			return
+		}
+		next := afterRevision
+		if next == 0 {
+			f.mu.Lock()
+			next = int64(len(f.events))
+			f.mu.Unlock()
+		}
+		for {
+			f.mu.Lock()
+			pending := slices.Clone(f.events[min(next, int64(len(f.events))):])
+			changed := f.changed
+			f.mu.Unlock()
+
+			for _, event := range pending {
+				if !yield(event, nil) {
+					return
+				}
+				next = event.Revision
+			}
+			select {
+			case <-ctx.Done():
+				yield(nil, status.FromContextError(ctx.Err()).Err())
+				return
+			case <-changed:
+			}
+		}
+	}
+}
+
+// BatchCreateBooks stores all the given books or none of them.
+func (f *Fake) BatchCreateBooks(ctx context.Context, inputs []*books.BookInput) ([]*books.Book, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	req := &bookiePb.BatchCreateBooksRequest{}
+	for _, input := range inputs {
+		req.Requests = append(req.Requests, createRequest(input))
+	}
+	if err := validation.Check(req); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, input := range inputs {
+		if _, ok := f.books[input.ID]; ok {
+			return nil, status.Errorf(codes.AlreadyExists, "Could not create books: already exists: %s", input.ID)
+		}
+	}
+	created := make([]*books.Book, 0, len(inputs))
+	for _, input := range inputs {
+		created = append(created, clone(f.create(input)))
+	}
+	return created, nil
+}
+
+// BatchGetBooks returns the live books with the given ids and the missing ids.
+func (f *Fake) BatchGetBooks(ctx context.Context, ids []string) (*books.BatchGetResult, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := &books.BatchGetResult{Books: []*books.Book{}, MissingIDs: []string{}}
+	for _, id := range ids {
+		book, err := f.live(id)
+		if err != nil {
+			result.MissingIDs = append(result.MissingIDs, id)
+			continue
+		}
+		result.Books = append(result.Books, clone(book))
+	}
+	return result, nil
+}
+
+// ImportBooks stores each valid row, skipping ids that already exist.
+func (f *Fake) ImportBooks(ctx context.Context, rows iter.Seq2[books.ImportRow, error]) (*books.ImportSummary, error) {
+	if err := f.callErr(ctx); err != nil {
+		return nil, err
+	}
+	summary := &books.ImportSummary{Failures: []books.ImportFailure{}}
+	fail := func(row int, reason string) {
+		summary.Failed++
+		summary.Failures = append(summary.Failures, books.ImportFailure{Row: row, Reason: reason})
+	}
+
+	for row, err := range rows {
+		if err != nil {
+			return nil, err
+		}
+		if row.Err != nil {
+			fail(row.Row, row.Err.Error())
+			continue
+		}
+		if violations := validation.Validate(createRequest(row.Book)); len(violations) > 0 {
+			reasons := make([]string, 0, len(violations))
+			for _, v := range violations {
+				reasons = append(reasons, v.GetDescription())
+			}
+			fail(row.Row, strings.Join(reasons, "; "))
+			continue
+		}
+
+		f.mu.Lock()
+		if _, ok := f.books[row.Book.ID]; ok {
+			summary.Skipped++
+		} else {
+			f.create(row.Book)
+			summary.Created++
+		}
+		f.mu.Unlock()
+	}
+	return summary, nil
+}
+
+// CheckHealth fails like every other call when Err is set.
+func (f *Fake) CheckHealth(ctx context.Context) error {
+	return f.callErr(ctx)
+}
+
+// callErr waits for Latency and returns the error a call made with ctx
+// fails with, if any.
+func (f *Fake) callErr(ctx context.Context) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	if f.Latency > 0 {
+		timer := time.NewTimer(f.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// create stores a new book and records its creation. f.mu must be held.
+func (f *Fake) create(input *books.BookInput) *books.Book {
+	id := input.ID
+	if id == "" {
+		f.nextID++
+		id = fmt.Sprintf("fake-%d", f.nextID)
+	}
+	book := f.put(&books.Book{
+		ID:          id,
+		Title:       input.Title,
+		Price:       input.Price,
+		Author:      input.Author,
+		Description: input.Description,
+	})
+	f.record("created", book)
+	return book
+}
+
+// put stores a copy of book. f.mu must be held unless f is not shared yet.
+func (f *Fake) put(book *books.Book) *books.Book {
+	stored := clone(book)
+	if _, ok := f.books[stored.ID]; !ok {
+		f.order = append(f.order, stored.ID)
+	}
+	f.books[stored.ID] = stored
+	return stored
+}
+
+// record appends a change event and wakes up watchers. f.mu must be held.
+func (f *Fake) record(eventType string, book *books.Book) {
+	f.events = append(f.events, &books.BookEvent{
+		Type:      eventType,
+		Revision:  int64(len(f.events) + 1),
+		Book:      clone(book),
+		EventTime: time.Now().UTC(),
+	})
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// live returns the stored, not soft-deleted book. f.mu must be held.
+func (f *Fake) live(id string) (*books.Book, error) {
+	book, ok := f.books[id]
+	if !ok || book.DeleteTime != nil {
+		return nil, status.Errorf(codes.NotFound, "Book with ID %s not found", id)
+	}
+	return book, nil
+}
+
+// list returns copies of the books in insertion order. f.mu must be held.
+func (f *Fake) list(showDeleted bool) []*books.Book {
+	list := make([]*books.Book, 0, len(f.order))
+	for _, id := range f.order {
+		if book := f.books[id]; showDeleted || book.DeleteTime == nil {
+			list = append(list, clone(book))
+		}
+	}
+	return list
+}
+
+func clone(book *books.Book) *books.Book {
+	c := *book
+	if book.DeleteTime != nil {
+		deleteTime := *book.DeleteTime
+		c.DeleteTime = &deleteTime
+	}
+	return &c
+}
+
+func createRequest(input *books.BookInput) *bookiePb.CreateBookRequest {
+	return &bookiePb.CreateBookRequest{
+		Id:          input.ID,
+		Title:       input.Title,
+		Description: input.Description,
+		Author:      input.Author,
+		Price:       int64(input.Price),
+	}
+}
@@ -3,40 +3,127 @@ package books
 
 import (
 	"context"
+	"errors"
+	"io"
+	"iter"
 	"log/slog"
-	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+	"github.com/sadhakbj/bookie-grpc/src/internal/auth"
+	"github.com/sadhakbj/bookie-grpc/src/internal/requestid"
 )
 
 // Book definiation
 type Book struct {
-	ID          string `json:"id"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Price       int        `json:"price"`
+	Author      string     `json:"author"`
+	Description string     `json:"description"`
+	DeleteTime  *time.Time `json:"delete_time,omitempty"`
+}
+
+// BookEvent is a change to the catalog reported by WatchBooks.
+type BookEvent struct {
+	// Type is one of "created", "updated" or "deleted".
+	Type      string    `json:"type"`
+	Revision  int64     `json:"revision"`
+	Book      *Book     `json:"book"`
+	EventTime time.Time `json:"event_time"`
+}
+
+// ListOptions controls which books GetBooks returns.
+type ListOptions struct {
+	// ShowDeleted includes soft-deleted books.
+	ShowDeleted bool
+	// PageSize is the maximum number of books to return. Zero uses the server default.
+	PageSize int
+	// PageToken continues a previous listing.
+	PageToken string
+	// Filter restricts the books returned, e.g. `author = "JK Rowling" AND price < 500`.
+	Filter string
+	// OrderBy sorts the books, e.g. "price desc, title".
+	OrderBy string
+}
+
+// StreamOptions controls which books StreamBooks returns.
+type StreamOptions struct {
+	// ShowDeleted includes soft-deleted books.
+	ShowDeleted bool
+	// Filter restricts the books returned, same syntax as ListOptions.Filter.
+	Filter string
+	// OrderBy sorts the books, same syntax as ListOptions.OrderBy.
+	OrderBy string
+}
+
+// BookPage is one page of books returned by GetBooks.
+type BookPage struct {
+	Books []*Book
+	// NextPageToken fetches the following page. Empty on the last page.
+	NextPageToken string
+	// TotalSize is the number of books across all pages.
+	TotalSize int
+	// Revision is the change-feed revision the page was read at. Watching
+	// from the first page's revision delivers every later change.
+	Revision int64
+}
+
+// BookInput holds the fields of a book to create. ID is optional; the server
+// generates one when it is empty.
+type BookInput struct {
+	ID          string `json:"id,omitempty"`
 	Title       string `json:"title"`
 	Price       int    `json:"price"`
 	Author      string `json:"author"`
 	Description string `json:"description"`
 }
 
-// GRPCClient is the structure.
+// BatchGetResult is the outcome of BatchGetBooks.
+type BatchGetResult struct {
+	Books      []*Book  `json:"books"`
+	MissingIDs []string `json:"missing_ids"`
+}
+
+// BookUpdate holds the fields to change in a partial book update.
+// Nil fields are left untouched.
+type BookUpdate struct {
+	Title       *string `json:"title"`
+	Price       *int    `json:"price"`
+	Author      *string `json:"author"`
+	Description *string `json:"description"`
+}
+
+// IsEmpty reports whether the update does not change any field.
+func (u *BookUpdate) IsEmpty() bool {
+	return u.Title == nil && u.Price == nil && u.Author == nil && u.Description == nil
+}
+
+// GRPCClient is the BookService backed by the Bookie gRPC server.
 type GRPCClient struct {
 	conn   *grpc.ClientConn
 	client bookiePb.BookieClient
+	health healthpb.HealthClient
 }
 
-// NewGRPCClient creates new instance of grpc client
-func NewGRPCClient() (*GRPCClient, error) {
+// NewGRPCClient creates new instance of grpc client connected to grpcServerAddr,
+// secured with creds, e.g. insecure.NewCredentials() for plaintext
+func NewGRPCClient(grpcServerAddr string, creds credentials.TransportCredentials) (*GRPCClient, error) {
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	}
-
-	// Get gRPC server address from environment variable, default to localhost:8020
-	grpcServerAddr := "localhost:8020"
-	if addr := os.Getenv("GRPC_SERVER_ADDR"); addr != "" {
-		grpcServerAddr = addr
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(requestid.UnaryClientInterceptor(), auth.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(requestid.StreamClientInterceptor(), auth.StreamClientInterceptor()),
 	}
 
 	slog.Info("Connecting to gRPC server", slog.String("address", grpcServerAddr))
@@ -50,52 +137,345 @@ func NewGRPCClient() (*GRPCClient, error) {
 	return &GRPCClient{
 		conn:   conn,
 		client: client,
+		health: healthpb.NewHealthClient(conn),
 	}, nil
 }
 
+// CheckHealth asks the health service of the gRPC server whether the Bookie
+// service is serving
+func (c *GRPCClient) CheckHealth(ctx context.Context) error {
+	res, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: bookiePb.Bookie_ServiceDesc.ServiceName})
+	if err != nil {
+		return err
+	}
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return status.Errorf(codes.Unavailable, "Book service is %s", res.GetStatus())
+	}
+	return nil
+}
+
 // Close closes the grpc connection
 func (c *GRPCClient) Close() error {
 	return c.conn.Close()
 }
 
-// GetBooks returns all the books from grpc book service
-func (c *GRPCClient) GetBooks() ([]*Book, error) {
-	res, err := c.client.ListBooks(context.Background(), &bookiePb.ListBookRequest{PerPage: 10})
+// GetBooks returns a page of books from grpc book service
+func (c *GRPCClient) GetBooks(ctx context.Context, opts ListOptions) (*BookPage, error) {
+	res, err := c.client.ListBooks(ctx, &bookiePb.ListBookRequest{
+		ShowDeleted: opts.ShowDeleted,
+		PageSize:    int32(opts.PageSize),
+		PageToken:   opts.PageToken,
+		Filter:      opts.Filter,
+		OrderBy:     opts.OrderBy,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert the response to []*Book
-	var bks []*Book
+	bks := make([]*Book, 0, len(res.GetBooks()))
 	for _, book := range res.GetBooks() {
-		book1 := &Book{
-			ID:          book.GetId(),
-			Title:       book.GetTitle(),
-			Description: book.GetDescription(),
-			Price:       int(book.GetPrice()),
-			Author:      book.GetAuthor(),
+		bks = append(bks, bookFromProto(book))
+	}
+
+	return &BookPage{
+		Books:         bks,
+		NextPageToken: res.GetNextPageToken(),
+		TotalSize:     int(res.GetTotalSize()),
+		Revision:      res.GetRevision(),
+	}, nil
+}
+
+// GetByID returns the resource with provided id
+func (c *GRPCClient) GetByID(ctx context.Context, id string) (*Book, error) {
+	res, err := c.client.GetByID(ctx, &bookiePb.GetByIDRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return bookFromProto(res.GetBook()), nil
+}
+
+// CreateBook creates a book and returns it with its generated id
+func (c *GRPCClient) CreateBook(ctx context.Context, input *BookInput) (*Book, error) {
+	res, err := c.client.CreateBook(ctx, input.toProto())
+	if err != nil {
+		return nil, err
+	}
+
+	return bookFromProto(res.GetBook()), nil
+}
+
+// UpdateBook changes the non-nil fields of update on the book with the given id
+func (c *GRPCClient) UpdateBook(ctx context.Context, id string, update *BookUpdate) (*Book, error) {
+	book := &bookiePb.Book{Id: id}
+	mask := &fieldmaskpb.FieldMask{}
+	if update.Title != nil {
+		book.Title = *update.Title
+		mask.Paths = append(mask.Paths, "title")
+	}
+	if update.Description != nil {
+		book.Description = *update.Description
+		mask.Paths = append(mask.Paths, "description")
+	}
+	if update.Author != nil {
+		book.Author = *update.Author
+		mask.Paths = append(mask.Paths, "author")
+	}
+	if update.Price != nil {
+		book.Price = int64(*update.Price)
+		mask.Paths = append(mask.Paths, "price")
+	}
+
+	res, err := c.client.UpdateBook(ctx, &bookiePb.UpdateBookRequest{
+		Book:       book,
+		UpdateMask: mask,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bookFromProto(res.GetBook()), nil
+}
+
+// StreamBooks iterates over every matching book using the server-streaming
+// StreamBooks RPC, so the whole catalog is never held in memory. Iteration
+// stops at the first error, which is yielded with a nil book. Cancelling ctx
+// or breaking out of the loop ends the stream.
+func (c *GRPCClient) StreamBooks(ctx context.Context, opts StreamOptions) iter.Seq2[*Book, error] {
+	return func(yield func(*Book, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := c.client.StreamBooks(ctx, &bookiePb.StreamBooksRequest{
+			ShowDeleted: opts.ShowDeleted,
+			Filter:      opts.Filter,
+			OrderBy:     opts.OrderBy,
+		})
+		if err != nil {
+			yield(nil, err)
+			return
 		}
 
-		bks = append(bks, book1)
+		for {
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, book := range res.GetBooks() {
+				if !yield(bookFromProto(book), nil) {
+					return
+				}
+			}
+		}
 	}
+}
+
+// WatchBooks iterates over catalog changes as they happen. A positive
+// afterRevision first replays the events after that revision. The stream only
+// ends when ctx is cancelled, the loop breaks or the server ends it with an
+// error, which is yielded with a nil event.
+func (c *GRPCClient) WatchBooks(ctx context.Context, afterRevision int64) iter.Seq2[*BookEvent, error] {
+	return func(yield func(*BookEvent, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := c.client.WatchBooks(ctx, &bookiePb.WatchBooksRequest{StartAfterRevision: afterRevision})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
 
+		for {
+			res, err := stream.Recv()
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			event := res.GetEvent()
+			if !yield(&BookEvent{
+				Type:      strings.ToLower(event.GetType().String()),
+				Revision:  event.GetRevision(),
+				Book:      bookFromProto(event.GetBook()),
+				EventTime: event.GetEventTime().AsTime(),
+			}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// BatchCreateBooks creates all the given books or none of them
+func (c *GRPCClient) BatchCreateBooks(ctx context.Context, inputs []*BookInput) ([]*Book, error) {
+	req := &bookiePb.BatchCreateBooksRequest{
+		Requests: make([]*bookiePb.CreateBookRequest, 0, len(inputs)),
+	}
+	for _, input := range inputs {
+		req.Requests = append(req.Requests, input.toProto())
+	}
+
+	res, err := c.client.BatchCreateBooks(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	bks := make([]*Book, 0, len(res.GetBooks()))
+	for _, book := range res.GetBooks() {
+		bks = append(bks, bookFromProto(book))
+	}
 	return bks, nil
 }
 
-// GetByID returns the resource with provided id
-func (c *GRPCClient) GetByID(id string) (*Book, error) {
-	res, err := c.client.GetByID(context.Background(), &bookiePb.GetByIDRequest{Id: id})
+// BatchGetBooks returns the books with the given ids and the ids that were not found
+func (c *GRPCClient) BatchGetBooks(ctx context.Context, ids []string) (*BatchGetResult, error) {
+	res, err := c.client.BatchGetBooks(ctx, &bookiePb.BatchGetBooksRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BatchGetResult{
+		Books:      make([]*Book, 0, len(res.GetBooks())),
+		MissingIDs: res.GetMissingIds(),
+	}
+	for _, book := range res.GetBooks() {
+		result.Books = append(result.Books, bookFromProto(book))
+	}
+	if result.MissingIDs == nil {
+		result.MissingIDs = []string{}
+	}
+	return result, nil
+}
+
+// DeleteBook soft-deletes the book with the given id
+func (c *GRPCClient) DeleteBook(ctx context.Context, id string) (*Book, error) {
+	res, err := c.client.DeleteBook(ctx, &bookiePb.DeleteBookRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return bookFromProto(res.GetBook()), nil
+}
+
+// UndeleteBook restores the soft-deleted book with the given id
+func (c *GRPCClient) UndeleteBook(ctx context.Context, id string) (*Book, error) {
+	res, err := c.client.UndeleteBook(ctx, &bookiePb.UndeleteBookRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return bookFromProto(res.GetBook()), nil
+}
+
+// toProto converts the input into a CreateBookRequest
+func (in *BookInput) toProto() *bookiePb.CreateBookRequest {
+	return &bookiePb.CreateBookRequest{
+		Id:          in.ID,
+		Title:       in.Title,
+		Description: in.Description,
+		Author:      in.Author,
+		Price:       int64(in.Price),
+	}
+}
+
+// bookFromProto converts a protobuf book into the JSON representation
+func bookFromProto(book *bookiePb.Book) *Book {
+	b := &Book{
+		ID:          book.GetId(),
+		Title:       book.GetTitle(),
+		Description: book.GetDescription(),
+		Price:       int(book.GetPrice()),
+		Author:      book.GetAuthor(),
+	}
+	if book.GetDeleteTime() != nil {
+		deleteTime := book.GetDeleteTime().AsTime()
+		b.DeleteTime = &deleteTime
+	}
+	return b
+}
+
+// maxImportFailures caps ImportSummary.Failures, matching the cap the server
+// applies to the failures it reports.
+const maxImportFailures = 1000
+
+// ImportRow is one parsed row of an import file. Err is set when the row
+// could not be parsed; it is reported as a failure without reaching the server.
+type ImportRow struct {
+	Row  int
+	Book *BookInput
+	Err  error
+}
+
+// ImportFailure describes a row that could not be imported.
+type ImportFailure struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// ImportSummary is the outcome of ImportBooks. Failed counts every failed
+// row, while Failures lists at most maxImportFailures of them.
+type ImportSummary struct {
+	Created  int             `json:"created"`
+	Skipped  int             `json:"skipped"`
+	Failed   int             `json:"failed"`
+	Failures []ImportFailure `json:"failures"`
+}
+
+// ImportBooks streams rows to the ImportBooks RPC as they are produced and
+// returns the combined summary. Rows are never collected in memory, so they
+// can be parsed straight from an upload. A non-nil error from rows, such as a
+// failed read, aborts the import; rows already sent stay imported.
+func (c *GRPCClient) ImportBooks(ctx context.Context, rows iter.Seq2[ImportRow, error]) (*ImportSummary, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.ImportBooks(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	book := &Book{
-		ID:          res.GetBook().GetId(),
-		Title:       res.GetBook().GetTitle(),
-		Description: res.GetBook().GetDescription(),
-		Price:       int(res.GetBook().GetPrice()),
-		Author:      res.GetBook().GetAuthor(),
+	summary := &ImportSummary{Failures: []ImportFailure{}}
+	for row, err := range rows {
+		if err != nil {
+			return nil, err
+		}
+		if row.Err != nil {
+			summary.Failed++
+			summary.addFailure(ImportFailure{Row: row.Row, Reason: row.Err.Error()})
+			continue
+		}
+		err := stream.Send(&bookiePb.ImportBooksRequest{Row: int64(row.Row), Book: row.Book.toProto()})
+		if errors.Is(err, io.EOF) {
+			// The server ended the stream; CloseAndRecv reports why.
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return book, nil
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Created = int(res.GetCreated())
+	summary.Skipped = int(res.GetSkipped())
+	summary.Failed += int(res.GetFailed())
+	for _, failure := range res.GetFailures() {
+		summary.addFailure(ImportFailure{Row: int(failure.GetRow()), Reason: failure.GetReason()})
+	}
+	slices.SortFunc(summary.Failures, func(a, b ImportFailure) int { return a.Row - b.Row })
+	return summary, nil
+}
+
+// addFailure lists failure unless maxImportFailures are listed already, so
+// a large upload of malformed rows cannot grow the summary without bound.
+func (s *ImportSummary) addFailure(failure ImportFailure) {
+	if len(s.Failures) < maxImportFailures {
+		s.Failures = append(s.Failures, failure)
+	}
 }
@@ -0,0 +1,38 @@
+package books
+
+import (
+	"context"
+	"iter"
+)
+
+// BookService is the set of book operations the HTTP controllers depend on.
+// GRPCClient implements it against the Bookie gRPC server; bookstest.Fake
+// implements it in memory for tests. Errors are gRPC statuses in both cases.
+// Every call is bound to ctx, so a cancelled or expired HTTP request stops
+// its gRPC calls.
+type BookService interface {
+	// GetBooks returns a page of books
+	GetBooks(ctx context.Context, opts ListOptions) (*BookPage, error)
+	// GetByID returns the resource with provided id
+	GetByID(ctx context.Context, id string) (*Book, error)
+	// CreateBook creates a book and returns it with its generated id
+	CreateBook(ctx context.Context, input *BookInput) (*Book, error)
+	// UpdateBook changes the non-nil fields of update on the book with the given id
+	UpdateBook(ctx context.Context, id string, update *BookUpdate) (*Book, error)
+	// DeleteBook soft-deletes the book with the given id
+	DeleteBook(ctx context.Context, id string) (*Book, error)
+	// UndeleteBook restores the soft-deleted book with the given id
+	UndeleteBook(ctx context.Context, id string) (*Book, error)
+	// StreamBooks iterates over every matching book
+	StreamBooks(ctx context.Context, opts StreamOptions) iter.Seq2[*Book, error]
+	// WatchBooks iterates over catalog changes after afterRevision as they happen
+	WatchBooks(ctx context.Context, afterRevision int64) iter.Seq2[*BookEvent, error]
+	// BatchCreateBooks creates all the given books or none of them
+	BatchCreateBooks(ctx context.Context, inputs []*BookInput) ([]*Book, error)
+	// BatchGetBooks returns the books with the given ids and the ids that were not found
+	BatchGetBooks(ctx context.Context, ids []string) (*BatchGetResult, error)
+	// ImportBooks creates books from rows and returns the combined summary
+	ImportBooks(ctx context.Context, rows iter.Seq2[ImportRow, error]) (*ImportSummary, error)
+}
+
+var _ BookService = (*GRPCClient)(nil)
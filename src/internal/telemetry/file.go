@@ -0,0 +1,110 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// fileClient is an otlptrace.Client that appends every batch of spans to a
+// file as one OTLP/JSON ExportTraceServiceRequest per line, the format read
+// by the OpenTelemetry Collector's otlpjsonfile receiver.
+type fileClient struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (c *fileClient) Start(context.Context) error {
+	file, err := os.OpenFile(c.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.file = file
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fileClient) Stop(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+func (c *fileClient) UploadTraces(_ context.Context, spans []*tracepb.ResourceSpans) error {
+	line, err := marshalOTLPJSON(&coltracepb.ExportTraceServiceRequest{ResourceSpans: spans})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return errors.New("trace file is closed")
+	}
+	_, err = c.file.Write(append(line, '\n'))
+	return err
+}
+
+// idFields are the bytes fields that OTLP/JSON encodes as hex.
+var idFields = map[string]bool{"traceId": true, "spanId": true, "parentSpanId": true}
+
+// marshalOTLPJSON encodes req as OTLP/JSON. It differs from the standard
+// protobuf JSON mapping in one way: trace and span ids are hex, not base64.
+func marshalOTLPJSON(req *coltracepb.ExportTraceServiceRequest) ([]byte, error) {
+	raw, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if err := hexIDs(doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// hexIDs rewrites the base64 id fields found anywhere in v as hex.
+func hexIDs(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if s, ok := value.(string); ok && idFields[key] {
+				id, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return fmt.Errorf("decode %s: %w", key, err)
+				}
+				v[key] = hex.EncodeToString(id)
+				continue
+			}
+			if err := hexIDs(value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := hexIDs(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
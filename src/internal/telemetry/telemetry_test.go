@@ -0,0 +1,108 @@
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/sadhakbj/bookie-grpc/src/internal/config"
+)
+
+func TestSetupOTLPFile(t *testing.T) {
+	defaultProvider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(defaultProvider)
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := Setup(context.Background(), "bookie-test", config.Tracing{Exporter: config.TracesExporterOTLPFile, File: path})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, child := otel.Tracer("test").Start(ctx, "child")
+	child.End()
+	parent.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open traces: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// OTLP/JSON, decoded by hand since its ids are hex rather than base64.
+	type span struct {
+		Name         string `json:"name"`
+		TraceID      string `json:"traceId"`
+		SpanID       string `json:"spanId"`
+		ParentSpanID string `json:"parentSpanId"`
+	}
+	var line struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string `json:"key"`
+					Value struct {
+						StringValue string `json:"stringValue"`
+					} `json:"value"`
+				} `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []span `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	spans := map[string]span{}
+	var service string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		for _, rs := range line.ResourceSpans {
+			for _, attr := range rs.Resource.Attributes {
+				if attr.Key == "service.name" {
+					service = attr.Value.StringValue
+				}
+			}
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					spans[s.Name] = s
+				}
+			}
+		}
+	}
+
+	if service != "bookie-test" {
+		t.Errorf("service.name = %q, want bookie-test", service)
+	}
+	if got := spans["parent"]; got.TraceID != parent.SpanContext().TraceID().String() ||
+		got.SpanID != parent.SpanContext().SpanID().String() || got.ParentSpanID != "" {
+		t.Errorf("parent span = %+v, want ids of %v", got, parent.SpanContext())
+	}
+	if got := spans["child"]; got.TraceID != parent.SpanContext().TraceID().String() ||
+		got.ParentSpanID != parent.SpanContext().SpanID().String() {
+		t.Errorf("child span = %+v, want a child of %v", got, parent.SpanContext())
+	}
+}
+
+func TestSetupNone(t *testing.T) {
+	defaultProvider := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), "bookie-test", config.Tracing{Exporter: config.TracesExporterNone})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+	if otel.GetTracerProvider() != defaultProvider {
+		t.Errorf("none exporter replaced the tracer provider")
+	}
+}
@@ -0,0 +1,78 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// Server returns the TLS configuration of the gRPC server. It presents the
+// certificate of r and, when r has a CA bundle, requires clients to present a
+// certificate signed by it (mutual TLS). Both are read from r on every
+// handshake, so reloads apply to new connections.
+func Server(r *Reloader) (*tls.Config, error) {
+	if _, err := r.certificate(); err != nil {
+		return nil, err
+	}
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool := r.caPool()
+		if pool == nil {
+			return nil, nil
+		}
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = pool
+		return cfg, nil
+	}
+	return base, nil
+}
+
+// Client returns the TLS configuration of a client of the gRPC server. The
+// server certificate is verified against the CA bundle of r, or the system
+// roots when r has none, for serverName or else the host dialled. When r has
+// a certificate it is presented to servers that ask for one (mutual TLS).
+func Client(r *Reloader, serverName string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+	if r.files.CertFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		}
+	}
+	if r.files.CAFile != "" {
+		// The default verification would pin the pool loaded at startup, so
+		// it is replaced by one that reads the current pool.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyServer(cs, r.caPool())
+		}
+	}
+	return cfg
+}
+
+// verifyServer does what crypto/tls does for a client when
+// InsecureSkipVerify is false, with roots as the trusted CAs.
+func verifyServer(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
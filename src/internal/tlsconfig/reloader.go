@@ -0,0 +1,149 @@
+// Package tlsconfig builds the TLS configurations of the gRPC server and of
+// its client in the BFF. Certificates, keys and CA bundles are read from PEM
+// files and reloaded when the files change, so rotated certificates are used
+// without a restart.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Files names the PEM files of one side of a connection. CertFile and KeyFile
+// are set together. CAFile is the bundle used to verify the peer.
+type Files struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Reloader holds the certificate and CA pool loaded from Files and reloads
+// them when any of the files changes. It is safe for concurrent use.
+type Reloader struct {
+	files Files
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime map[string]time.Time
+}
+
+// NewReloader loads files, failing if any of them is missing or invalid.
+func NewReloader(files Files) (*Reloader, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, errors.New("certificate and key files must be set together")
+	}
+	r := &Reloader{files: files}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Run checks the files every interval until ctx is cancelled and reloads them
+// when one has a new modification time. A failed reload is logged and the
+// previous certificates stay in use.
+func (r *Reloader) Run(ctx context.Context, logger *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				logger.Error("Failed to reload TLS certificates", "error", err)
+				continue
+			}
+			if reloaded {
+				logger.Info("Reloaded TLS certificates", "cert", r.files.CertFile, "ca", r.files.CAFile)
+			}
+		}
+	}
+}
+
+// Reload loads the files again if any of them changed since the last load
+// and reports whether it did.
+func (r *Reloader) Reload() (bool, error) {
+	modTime := make(map[string]time.Time, 3)
+	for _, path := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		modTime[path] = info.ModTime()
+	}
+
+	r.mu.RLock()
+	changed := !sameModTimes(r.modTime, modTime)
+	r.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	var cert *tls.Certificate
+	if r.files.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return false, fmt.Errorf("load key pair: %w", err)
+		}
+		cert = &pair
+	}
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		pem, err := os.ReadFile(r.files.CAFile)
+		if err != nil {
+			return false, err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return false, fmt.Errorf("no certificates found in %s", r.files.CAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.pool, r.modTime = cert, pool, modTime
+	r.mu.Unlock()
+	return true, nil
+}
+
+func sameModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, t := range a {
+		if !t.Equal(b[path]) {
+			return false
+		}
+	}
+	return true
+}
+
+// certificate returns the current certificate, or an error when there is none.
+func (r *Reloader) certificate() (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cert == nil {
+		return nil, errors.New("no certificate configured")
+	}
+	return r.cert, nil
+}
+
+// caPool returns the current CA pool, nil when no CA file is configured.
+func (r *Reloader) caPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
@@ -0,0 +1,278 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// testCA is an ephemeral certificate authority.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var serial int64
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func newCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key := newKey(t)
+	serial++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a leaf certificate for name, valid for both server and client
+// authentication, and returns its PEM certificate and key.
+func (ca *testCA) issue(t *testing.T, name string) ([]byte, []byte) {
+	t.Helper()
+	key := newKey(t)
+	serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("issue %s: %v", name, err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile writes data to name in dir and moves its modification time
+// forward, so a rewrite within the same clock tick is still noticed.
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	var mtime time.Time
+	if info, err := os.Stat(path); err == nil {
+		mtime = info.ModTime().Add(time.Second)
+	} else {
+		mtime = time.Now()
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("chtimes %s: %v", name, err)
+	}
+	return path
+}
+
+// writePair writes a certificate and key issued by ca for name.
+func writePair(t *testing.T, dir, prefix string, ca *testCA, name string) (string, string) {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, name)
+	return writeFile(t, dir, prefix+".crt", certPEM), writeFile(t, dir, prefix+".key", keyPEM)
+}
+
+// serveHealth starts a gRPC server with the TLS configuration of files and
+// returns its address.
+func serveHealth(t *testing.T, files Files) (string, *Reloader) {
+	t.Helper()
+	reloader, err := NewReloader(files)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	cfg, err := Server(reloader)
+	if err != nil {
+		t.Fatalf("Server: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(cfg)))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String(), reloader
+}
+
+// check calls the health service at addr as a client configured with files.
+func check(t *testing.T, addr string, files Files, serverName string) error {
+	t.Helper()
+	reloader, err := NewReloader(files)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(Client(reloader, serverName))))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, rogue := newCA(t, "bookie-ca"), newCA(t, "rogue-ca")
+	caFile := writeFile(t, dir, "ca.pem", ca.pem)
+	rogueCAFile := writeFile(t, dir, "rogue-ca.pem", rogue.pem)
+	serverCert, serverKey := writePair(t, dir, "server", ca, "grpc-server")
+	clientCert, clientKey := writePair(t, dir, "client", ca, "http-client")
+	rogueCert, rogueKey := writePair(t, dir, "rogue", rogue, "http-client")
+
+	addr, _ := serveHealth(t, Files{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+
+	tests := []struct {
+		name       string
+		files      Files
+		serverName string
+		wantErr    bool
+	}{
+		{name: "trusted client", files: Files{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile}, serverName: "grpc-server"},
+		{name: "client cert from another CA", files: Files{CertFile: rogueCert, KeyFile: rogueKey, CAFile: caFile}, serverName: "grpc-server", wantErr: true},
+		{name: "no client cert", files: Files{CAFile: caFile}, serverName: "grpc-server", wantErr: true},
+		{name: "server cert from untrusted CA", files: Files{CertFile: clientCert, KeyFile: clientKey, CAFile: rogueCAFile}, serverName: "grpc-server", wantErr: true},
+		{name: "wrong server name", files: Files{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile}, serverName: "elsewhere", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := check(t, addr, tt.files, tt.serverName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerTLSWithoutClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCA(t, "bookie-ca")
+	caFile := writeFile(t, dir, "ca.pem", ca.pem)
+	serverCert, serverKey := writePair(t, dir, "server", ca, "grpc-server")
+
+	addr, _ := serveHealth(t, Files{CertFile: serverCert, KeyFile: serverKey})
+	if err := check(t, addr, Files{CAFile: caFile}, "grpc-server"); err != nil {
+		t.Errorf("Check without client cert: %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	oldCA, newCA := newCA(t, "old-ca"), newCA(t, "new-ca")
+	caFile := writeFile(t, dir, "ca.pem", oldCA.pem)
+	serverCert, serverKey := writePair(t, dir, "server", oldCA, "grpc-server")
+	oldClientCert, oldClientKey := writePair(t, dir, "old-client", oldCA, "http-client")
+	newClientCert, newClientKey := writePair(t, dir, "new-client", newCA, "http-client")
+	bothCAs := writeFile(t, dir, "both-ca.pem", append(append([]byte{}, oldCA.pem...), newCA.pem...))
+
+	addr, reloader := serveHealth(t, Files{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+	oldClient := Files{CertFile: oldClientCert, KeyFile: oldClientKey, CAFile: bothCAs}
+	newClient := Files{CertFile: newClientCert, KeyFile: newClientKey, CAFile: bothCAs}
+
+	if reloaded, err := reloader.Reload(); reloaded || err != nil {
+		t.Fatalf("Reload of unchanged files = %v, %v, want false, nil", reloaded, err)
+	}
+	if err := check(t, addr, newClient, "grpc-server"); err == nil {
+		t.Fatalf("client of the new CA accepted before rotation")
+	}
+
+	// Rotate the server certificate and the trusted client CA to the new CA.
+	writeFile(t, dir, "ca.pem", newCA.pem)
+	writePair(t, dir, "server", newCA, "grpc-server")
+	if reloaded, err := reloader.Reload(); !reloaded || err != nil {
+		t.Fatalf("Reload after rotation = %v, %v, want true, nil", reloaded, err)
+	}
+
+	if err := check(t, addr, newClient, "grpc-server"); err != nil {
+		t.Errorf("client of the new CA rejected after rotation: %v", err)
+	}
+	if err := check(t, addr, oldClient, "grpc-server"); err == nil {
+		t.Errorf("client of the old CA accepted after rotation")
+	}
+	conn, err := tls.Dial("tcp", addr, &tls.Config{
+		RootCAs:      mustPool(t, newCA.pem),
+		ServerName:   "grpc-server",
+		NextProtos:   []string{"h2"},
+		Certificates: []tls.Certificate{mustPair(t, newClientCert, newClientKey)},
+	})
+	if err != nil {
+		t.Fatalf("dial after rotation: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if issuer := conn.ConnectionState().PeerCertificates[0].Issuer.CommonName; issuer != "new-ca" {
+		t.Errorf("server certificate issued by %q after rotation, want new-ca", issuer)
+	}
+
+	// A broken file is reported and the rotated certificates stay in use.
+	writeFile(t, dir, "server.crt", []byte("not a certificate"))
+	if _, err := reloader.Reload(); err == nil {
+		t.Errorf("Reload of a broken certificate succeeded")
+	}
+	if err := check(t, addr, newClient, "grpc-server"); err != nil {
+		t.Errorf("client rejected after a failed reload: %v", err)
+	}
+}
+
+func mustPool(t *testing.T, pemData []byte) *x509.CertPool {
+	t.Helper()
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		t.Fatalf("bad CA PEM")
+	}
+	return pool
+}
+
+func mustPair(t *testing.T, certFile, keyFile string) tls.Certificate {
+	t.Helper()
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("load pair: %v", err)
+	}
+	return pair
+}
@@ -2,30 +2,162 @@
 package utils
 
 import (
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-// HandleGRPCError converts gRPC errors to appropriate HTTP responses.
-func HandleGRPCError(w http.ResponseWriter, err error) {
-	log.Println("Error:", err)
-	status, msg := GrpcErrorToHTTPStatus(err)
+// StatusClientClosedRequest is the non-standard status, popularised by nginx,
+// for requests the client gave up on before a response was ready.
+const StatusClientClosedRequest = 499
 
-	JSONResponse(w, status, false, msg, nil)
+// Messages that replace status messages which may leak implementation
+// details. Unavailable and DeadlineExceeded are usually raised by the client
+// transport, whose messages name internal hosts and ports.
+const (
+	genericErrorMessage     = "Something went wrong"
+	unavailableErrorMessage = "Service unavailable"
+	timeoutErrorMessage     = "Request timed out"
+)
+
+// Types of ErrorDetail.
+const (
+	ErrorTypeFieldViolation = "field_violation"
+	ErrorTypeErrorInfo      = "error_info"
+	ErrorTypeRetryInfo      = "retry_info"
+)
+
+// ErrorDetail is one entry of the "errors" field of an error response,
+// decoded from the details attached to a gRPC status. Type tells which of
+// the other fields are set.
+type ErrorDetail struct {
+	Type string `json:"type"`
+	// Field is the request field a field_violation refers to.
+	Field string `json:"field,omitempty"`
+	// Reason is a machine-readable cause, e.g. "REQUIRED" or "RATE_LIMITED".
+	Reason      string            `json:"reason,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Domain      string            `json:"domain,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	// RetryDelay is how long a retry_info asks clients to wait, e.g. "1.5s".
+	RetryDelay string `json:"retry_delay,omitempty"`
+}
+
+// HandleGRPCError converts gRPC errors to appropriate HTTP responses. The
+// status details are listed under "errors", and a RetryInfo detail sets the
+// Retry-After header. The error is logged in full with the logger of the
+// request context, so the line carries the request id and the detail hidden
+// from the response.
+func HandleGRPCError(w http.ResponseWriter, req *http.Request, err error) {
+	code, msg := GrpcErrorToHTTPStatus(err)
+	level := slog.LevelWarn
+	if code >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	LoggerFromContext(req.Context()).Log(req.Context(), level, "gRPC call failed", "error", err, "status", code)
+
+	st := status.Convert(err)
+	if seconds, ok := retryAfterSeconds(st); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	JSONErrorResponse(w, code, msg, GRPCErrorDetails(st))
 }
 
-// GrpcErrorToHTTPStatus converts gRPC error codes to HTTP status codes.
+// GrpcErrorToHTTPStatus converts gRPC error codes to HTTP status codes. The
+// status message is passed through except for server-side and transport
+// failures, whose messages are replaced by fixed ones.
 func GrpcErrorToHTTPStatus(err error) (int, string) {
-	if st, ok := status.FromError(err); ok {
-		switch st.Code() {
-		case codes.NotFound:
-			return http.StatusNotFound, "Item not found"
-		default:
-			return http.StatusInternalServerError, "Something went wrong"
+	st, ok := status.FromError(err)
+	if !ok {
+		return http.StatusInternalServerError, genericErrorMessage
+	}
+	switch st.Code() {
+	case codes.Unknown, codes.Internal, codes.DataLoss:
+		return HTTPStatusFromCode(st.Code()), genericErrorMessage
+	case codes.Unavailable:
+		return HTTPStatusFromCode(st.Code()), unavailableErrorMessage
+	case codes.DeadlineExceeded:
+		return HTTPStatusFromCode(st.Code()), timeoutErrorMessage
+	default:
+		return HTTPStatusFromCode(st.Code()), st.Message()
+	}
+}
+
+// HTTPStatusFromCode returns the HTTP status matching a gRPC code, following
+// the mapping in google/rpc/code.proto.
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return StatusClientClosedRequest
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCErrorDetails decodes the BadRequest, ErrorInfo and RetryInfo details of
+// a status. Other detail types are skipped.
+func GRPCErrorDetails(st *status.Status) []ErrorDetail {
+	var details []ErrorDetail
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				details = append(details, ErrorDetail{
+					Type:        ErrorTypeFieldViolation,
+					Field:       v.GetField(),
+					Reason:      v.GetReason(),
+					Description: v.GetDescription(),
+				})
+			}
+		case *errdetails.ErrorInfo:
+			details = append(details, ErrorDetail{
+				Type:     ErrorTypeErrorInfo,
+				Reason:   d.GetReason(),
+				Domain:   d.GetDomain(),
+				Metadata: d.GetMetadata(),
+			})
+		case *errdetails.RetryInfo:
+			details = append(details, ErrorDetail{
+				Type:       ErrorTypeRetryInfo,
+				RetryDelay: d.GetRetryDelay().AsDuration().String(),
+			})
+		}
+	}
+	return details
+}
+
+// retryAfterSeconds returns the RetryInfo delay of a status rounded up to
+// whole seconds, as the Retry-After header requires.
+func retryAfterSeconds(st *status.Status) (int, bool) {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return int(math.Ceil(info.GetRetryDelay().AsDuration().Seconds())), true
 		}
 	}
-	return http.StatusInternalServerError, "Something went wrong"
+	return 0, false
 }
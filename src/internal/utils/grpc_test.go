@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestGrpcErrorToHTTPStatus(t *testing.T) {
+	tests := []struct {
+		err      error
+		wantCode int
+		wantMsg  string
+	}{
+		{status.Error(codes.InvalidArgument, "Invalid filter"), http.StatusBadRequest, "Invalid filter"},
+		{status.Error(codes.NotFound, "Book with ID 1 not found"), http.StatusNotFound, "Book with ID 1 not found"},
+		{status.Error(codes.AlreadyExists, "exists"), http.StatusConflict, "exists"},
+		{status.Error(codes.PermissionDenied, "denied"), http.StatusForbidden, "denied"},
+		{status.Error(codes.Unauthenticated, "who"), http.StatusUnauthorized, "who"},
+		{status.Error(codes.ResourceExhausted, "slow down"), http.StatusTooManyRequests, "slow down"},
+		{status.Error(codes.FailedPrecondition, "not yet"), http.StatusBadRequest, "not yet"},
+		{status.Error(codes.OutOfRange, "compacted"), http.StatusBadRequest, "compacted"},
+		{status.Error(codes.Unimplemented, "nope"), http.StatusNotImplemented, "nope"},
+		{status.Error(codes.Unavailable, "connection error: desc = transport: dial tcp grpc-server:8020: connect: connection refused"),
+			http.StatusServiceUnavailable, unavailableErrorMessage},
+		{status.Error(codes.DeadlineExceeded, "context deadline exceeded"), http.StatusGatewayTimeout, timeoutErrorMessage},
+		{status.Error(codes.Canceled, "gone"), StatusClientClosedRequest, "gone"},
+		{status.Error(codes.Internal, "sql: database is locked"), http.StatusInternalServerError, genericErrorMessage},
+		{errors.New("plain error"), http.StatusInternalServerError, genericErrorMessage},
+	}
+	for _, tt := range tests {
+		code, msg := GrpcErrorToHTTPStatus(tt.err)
+		if code != tt.wantCode || msg != tt.wantMsg {
+			t.Errorf("GrpcErrorToHTTPStatus(%v) = %d, %q, want %d, %q", tt.err, code, msg, tt.wantCode, tt.wantMsg)
+		}
+	}
+}
+
+func TestHandleGRPCErrorDetails(t *testing.T) {
+	st, err := status.New(codes.ResourceExhausted, "Too many imports").WithDetails(
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "title", Reason: "REQUIRED", Description: "title is required"},
+		}},
+		&errdetails.ErrorInfo{Reason: "RATE_LIMITED", Domain: "bookie", Metadata: map[string]string{"limit": "5"}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(1500 * time.Millisecond)},
+	)
+	if err != nil {
+		t.Fatalf("WithDetails: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	HandleGRPCError(rec, httptest.NewRequest(http.MethodGet, "/books", nil), st.Err())
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+
+	var body struct {
+		Success bool          `json:"success"`
+		Message string        `json:"message"`
+		Errors  []ErrorDetail `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Success || body.Message != "Too many imports" {
+		t.Errorf("envelope = %+v", body)
+	}
+	want := []ErrorDetail{
+		{Type: ErrorTypeFieldViolation, Field: "title", Reason: "REQUIRED", Description: "title is required"},
+		{Type: ErrorTypeErrorInfo, Reason: "RATE_LIMITED", Domain: "bookie", Metadata: map[string]string{"limit": "5"}},
+		{Type: ErrorTypeRetryInfo, RetryDelay: "1.5s"},
+	}
+	if len(body.Errors) != len(want) {
+		t.Fatalf("errors = %+v, want %+v", body.Errors, want)
+	}
+	for i := range want {
+		got := body.Errors[i]
+		if got.Type != want[i].Type || got.Field != want[i].Field || got.Reason != want[i].Reason ||
+			got.Description != want[i].Description || got.Domain != want[i].Domain ||
+			got.RetryDelay != want[i].RetryDelay || got.Metadata["limit"] != want[i].Metadata["limit"] {
+			t.Errorf("errors[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestHandleGRPCErrorHidesTransportDetails(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := ContextWithLogger(context.Background(), slog.New(slog.NewJSONHandler(&logs, nil)))
+	err := status.Error(codes.Unavailable, "connection error: desc = transport: dial tcp grpc-server:8020: connect: connection refused")
+
+	rec := httptest.NewRecorder()
+	HandleGRPCError(rec, httptest.NewRequestWithContext(ctx, http.MethodGet, "/books/1", nil), err)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if strings.Contains(rec.Body.String(), "grpc-server") {
+		t.Errorf("response leaks the upstream address: %s", rec.Body)
+	}
+	if !strings.Contains(logs.String(), "grpc-server:8020") {
+		t.Errorf("log does not record the transport error: %s", logs.String())
+	}
+}
+
+func TestHandleGRPCErrorWithoutDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HandleGRPCError(rec, httptest.NewRequest(http.MethodGet, "/books/1", nil), status.Error(codes.NotFound, "Book with ID 1 not found"))
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if _, ok := body["errors"]; ok {
+		t.Errorf("body has errors field without details: %v", body)
+	}
+	if rec.Header().Get("Retry-After") != "" {
+		t.Errorf("unexpected Retry-After header")
+	}
+}
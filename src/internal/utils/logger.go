@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"time"
@@ -24,3 +25,20 @@ func InitializeLogger(name string, addSource bool) *slog.Logger {
 	}))
 	return logger
 }
+
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx carrying logger, typically one
+// annotated with request-scoped attributes such as the request id.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext returns the logger stored by ContextWithLogger, or
+// slog.Default() when there is none.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -6,14 +6,50 @@ import (
 	"net/http"
 )
 
+// Pagination tells API consumers how to continue a paginated listing.
+type Pagination struct {
+	NextPageToken string `json:"next_page_token"`
+	TotalSize     int    `json:"total_size"`
+	// Revision is the change revision the page was read at, to resume
+	// GET /books/events from without missing changes.
+	Revision int64 `json:"revision"`
+}
+
 // JSONResponse writes a standardized JSON response to the HTTP response writer.
 func JSONResponse(w http.ResponseWriter, statusCode int, success bool, message string, data interface{}) {
-	response := map[string]interface{}{
+	writeJSON(w, statusCode, map[string]interface{}{
 		"success": success,
 		"message": message,
 		"data":    data,
+	})
+}
+
+// JSONErrorResponse writes a failed standardized JSON response. errs is
+// listed under "errors" when it is not empty.
+func JSONErrorResponse(w http.ResponseWriter, statusCode int, message string, errs []ErrorDetail) {
+	response := map[string]interface{}{
+		"success": false,
+		"message": message,
+		"data":    nil,
+	}
+	if len(errs) > 0 {
+		response["errors"] = errs
 	}
+	writeJSON(w, statusCode, response)
+}
+
+// JSONPageResponse writes a successful standardized JSON response holding one
+// page of a listing, with the pagination details next to the data.
+func JSONPageResponse(w http.ResponseWriter, message string, data interface{}, pagination Pagination) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"message":    message,
+		"data":       data,
+		"pagination": pagination,
+	})
+}
 
+func writeJSON(w http.ResponseWriter, statusCode int, response map[string]interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// ServerStreamWithContext returns ss with its context replaced by ctx, for
+// stream interceptors that add values to the context of a call.
+func ServerStreamWithContext(ctx context.Context, ss grpc.ServerStream) grpc.ServerStream {
+	return &contextStream{ServerStream: ss, ctx: ctx}
+}
+
+// contextStream replaces the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Reasons set on errdetails.BadRequest_FieldViolation so clients can react
+// to a violation without parsing its description.
+const (
+	ReasonRequired      = "REQUIRED"
+	ReasonTooLong       = "TOO_LONG"
+	ReasonOutOfRange    = "OUT_OF_RANGE"
+	ReasonTooFewItems   = "TOO_FEW_ITEMS"
+	ReasonTooManyItems  = "TOO_MANY_ITEMS"
+	ReasonInvalidFormat = "INVALID_FORMAT"
+	ReasonNotAllowed    = "NOT_ALLOWED"
+	ReasonDuplicate     = "DUPLICATE"
+)
+
+func fail(path, reason, format string, args ...any) []fieldError {
+	return []fieldError{{path: path, reason: reason, msg: fmt.Sprintf(format, args...)}}
+}
+
+// required rejects empty strings and lists, zero numbers and unset messages.
+func required() check {
+	return func(path string, _ protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError {
+		var empty bool
+		switch x := v.Interface().(type) {
+		case protoreflect.List:
+			empty = x.Len() == 0
+		case protoreflect.Message:
+			empty = !x.IsValid()
+		case string:
+			empty = x == ""
+		case []byte:
+			empty = len(x) == 0
+		default:
+			empty = reflect.ValueOf(x).IsZero()
+		}
+		if empty {
+			return fail(path, ReasonRequired, "is required")
+		}
+		return nil
+	}
+}
+
+// maxLen limits a string to n characters.
+func maxLen(n int) check {
+	return func(path string, _ protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError {
+		if utf8.RuneCountInString(v.String()) > n {
+			return fail(path, ReasonTooLong, "must be at most %d characters", n)
+		}
+		return nil
+	}
+}
+
+// pattern requires a non-empty string to match re; what describes the
+// accepted format for the violation message.
+func pattern(re *regexp.Regexp, what string) check {
+	return func(path string, _ protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError {
+		if s := v.String(); s != "" && !re.MatchString(s) {
+			return fail(path, ReasonInvalidFormat, "must only contain %s", what)
+		}
+		return nil
+	}
+}
+
+// minInt rejects integers below n.
+func minInt(n int64) check {
+	return func(path string, _ protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError {
+		if v.Int() >= n {
+			return nil
+		}
+		if n == 0 {
+			return fail(path, ReasonOutOfRange, "must not be negative")
+		}
+		return fail(path, ReasonOutOfRange, "must be at least %d", n)
+	}
+}
+
+// maxInt rejects integers above n.
+func maxInt(n int64) check {
+	return func(path string, _ protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError {
+		if v.Int() > n {
+			return fail(path, ReasonOutOfRange, "must be at most %d", n)
+		}
+		return nil
+	}
+}
+
+// itemCount requires a repeated field to hold between lo and hi items.
+func itemCount(lo, hi int) check {
+	return func(path string, _ protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError {
+		switch n := v.List().Len(); {
+		case n < lo:
+			return fail(path, ReasonTooFewItems, "must contain at least %d items", lo)
+		case n > hi:
+			return fail(path, ReasonTooManyItems, "must contain at most %d items", hi)
+		}
+		return nil
+	}
+}
+
+// oneOf restricts a string to the allowed values.
+func oneOf(allowed ...string) check {
+	return func(path string, _ protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError {
+		if !slices.Contains(allowed, v.String()) {
+			return fail(path, ReasonNotAllowed, "must be one of %s", strings.Join(allowed, ", "))
+		}
+		return nil
+	}
+}
+
+// each applies checks to every element of a repeated scalar field. Element
+// paths are indexed, e.g. "ids[2]".
+func each(checks ...check) check {
+	return func(path string, fd protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError {
+		var errs []fieldError
+		list := v.List()
+		for i := range list.Len() {
+			for _, c := range checks {
+				errs = append(errs, c(fmt.Sprintf("%s[%d]", path, i), fd, list.Get(i))...)
+			}
+		}
+		return errs
+	}
+}
@@ -0,0 +1,137 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+const (
+	maxIDLength          = 64
+	maxTitleLength       = 200
+	maxAuthorLength      = 200
+	maxDescriptionLength = 5000
+	maxPrice             = 1_000_000_000
+	maxPageTokenLength   = 2048
+	maxFilterLength      = 1024
+	maxOrderByLength     = 256
+	// maxBatchSize caps the number of items in BatchCreateBooks and BatchGetBooks.
+	maxBatchSize = 1000
+)
+
+// UpdatableBookFields are the Book field mask paths UpdateBook accepts.
+var UpdatableBookFields = []string{"title", "description", "author", "price"}
+
+// bookIDPattern keeps client-chosen IDs safe to use as a URL path segment.
+var bookIDPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]+$`)
+
+// bookIDChars describes bookIDPattern in violation messages.
+const bookIDChars = "letters, digits, '.', '_', '~' and '-'"
+
+// lookupID is the rule for the id of an existing book.
+var lookupID = []check{required(), maxLen(maxIDLength)}
+
+// rules holds the constraints for every Bookie request message, and for the
+// messages nested in them. ImportBooksRequest has none of its own: each row
+// is checked by the handler against the CreateBookRequest rules so that one
+// bad row is reported instead of aborting the upload.
+var rules = map[protoreflect.FullName]messageRules{
+	name(&bookiePb.Book{}): {fields: []fieldRule{
+		{"id", []check{maxLen(maxIDLength)}},
+		{"title", []check{maxLen(maxTitleLength)}},
+		{"description", []check{maxLen(maxDescriptionLength)}},
+		{"author", []check{maxLen(maxAuthorLength)}},
+		{"price", []check{minInt(0), maxInt(maxPrice)}},
+	}},
+	name(&bookiePb.ListBookRequest{}): {fields: []fieldRule{
+		{"perPage", []check{minInt(0)}},
+		{"page_size", []check{minInt(0)}},
+		{"page_token", []check{maxLen(maxPageTokenLength)}},
+		{"filter", []check{maxLen(maxFilterLength)}},
+		{"order_by", []check{maxLen(maxOrderByLength)}},
+	}},
+	name(&bookiePb.CreateBookRequest{}): {fields: []fieldRule{
+		{"id", []check{maxLen(maxIDLength), pattern(bookIDPattern, bookIDChars)}},
+		{"title", []check{required(), maxLen(maxTitleLength)}},
+		{"description", []check{maxLen(maxDescriptionLength)}},
+		{"author", []check{maxLen(maxAuthorLength)}},
+		{"price", []check{minInt(0), maxInt(maxPrice)}},
+	}},
+	name(&bookiePb.GetByIDRequest{}): {fields: []fieldRule{
+		{"id", lookupID},
+	}},
+	name(&bookiePb.UpdateBookRequest{}): {
+		fields: []fieldRule{
+			{"book", []check{required()}},
+			{"book.id", []check{required()}},
+			{"update_mask.paths", []check{each(oneOf(UpdatableBookFields...))}},
+		},
+		check: updatedTitleRequired,
+	},
+	name(&bookiePb.DeleteBookRequest{}): {fields: []fieldRule{
+		{"id", lookupID},
+	}},
+	name(&bookiePb.UndeleteBookRequest{}): {fields: []fieldRule{
+		{"id", lookupID},
+	}},
+	name(&bookiePb.StreamBooksRequest{}): {fields: []fieldRule{
+		{"filter", []check{maxLen(maxFilterLength)}},
+		{"order_by", []check{maxLen(maxOrderByLength)}},
+		{"chunk_size", []check{minInt(0)}},
+	}},
+	name(&bookiePb.WatchBooksRequest{}): {fields: []fieldRule{
+		{"start_after_revision", []check{minInt(0)}},
+	}},
+	name(&bookiePb.BatchCreateBooksRequest{}): {
+		fields: []fieldRule{
+			{"requests", []check{itemCount(1, maxBatchSize)}},
+		},
+		check: uniqueBatchIDs,
+	},
+	name(&bookiePb.BatchGetBooksRequest{}): {fields: []fieldRule{
+		{"ids", []check{itemCount(1, maxBatchSize), each(lookupID...)}},
+	}},
+}
+
+func name(msg proto.Message) protoreflect.FullName {
+	return msg.ProtoReflect().Descriptor().FullName()
+}
+
+// updatedTitleRequired rejects clearing the title, which CreateBook requires.
+// An empty update mask replaces every updatable field, title included.
+func updatedTitleRequired(msg proto.Message) []fieldError {
+	req := msg.(*bookiePb.UpdateBookRequest)
+	paths := req.GetUpdateMask().GetPaths()
+	if req.GetBook() == nil || (len(paths) > 0 && !slices.Contains(paths, "title")) {
+		return nil
+	}
+	if req.GetBook().GetTitle() == "" {
+		return fail("book.title", ReasonRequired, "is required")
+	}
+	return nil
+}
+
+// uniqueBatchIDs rejects client-chosen IDs repeated within one batch.
+func uniqueBatchIDs(msg proto.Message) []fieldError {
+	var errs []fieldError
+	items := msg.(*bookiePb.BatchCreateBooksRequest).GetRequests()
+	firstIndex := make(map[string]int, len(items))
+	for i, item := range items {
+		id := item.GetId()
+		if id == "" {
+			continue
+		}
+		if j, ok := firstIndex[id]; ok {
+			errs = append(errs, fail(fmt.Sprintf("requests[%d].id", i), ReasonDuplicate,
+				"duplicates requests[%d].id %s", j, id)...)
+			continue
+		}
+		firstIndex[id] = i
+	}
+	return errs
+}
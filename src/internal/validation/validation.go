@@ -0,0 +1,154 @@
+// Package validation checks Bookie request messages against declarative
+// per-message rules. Violations are reported as errdetails.BadRequest field
+// violations so clients can point at the offending field.
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// messageRules are the constraints for one message type.
+type messageRules struct {
+	// fields are checked in order. A path may reach into nested messages,
+	// e.g. "book.id"; the rule is skipped when an intermediate message is unset.
+	fields []fieldRule
+	// check runs after the field rules for constraints spanning several fields.
+	check func(msg proto.Message) []fieldError
+}
+
+type fieldRule struct {
+	path   string
+	checks []check
+}
+
+// check inspects the value of the field fd found at path.
+type check func(path string, fd protoreflect.FieldDescriptor, v protoreflect.Value) []fieldError
+
+// fieldError is a single violation. path is relative to the message being
+// validated and gets prefixed as errors bubble up from nested messages.
+type fieldError struct {
+	path   string
+	reason string
+	msg    string
+}
+
+// Validate checks msg, and every nested message with rules of its own,
+// against the registered rules. It returns nil when msg is valid or has no
+// rules.
+func Validate(msg proto.Message) []*errdetails.BadRequest_FieldViolation {
+	errs := validateMessage(msg.ProtoReflect())
+	if len(errs) == 0 {
+		return nil
+	}
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(errs))
+	for _, e := range errs {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       e.path,
+			Reason:      e.reason,
+			Description: e.path + " " + e.msg,
+		})
+	}
+	return violations
+}
+
+// Check is like Validate but returns an InvalidArgument status carrying the
+// violations as errdetails.BadRequest, or nil when msg is valid.
+func Check(msg proto.Message) error {
+	violations := Validate(msg)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	text := fmt.Sprintf("Invalid %s: %s", msg.ProtoReflect().Descriptor().Name(), violations[0].GetDescription())
+	if len(violations) > 1 {
+		text += fmt.Sprintf(" (and %d more)", len(violations)-1)
+	}
+	st := status.New(codes.InvalidArgument, text)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+func validateMessage(m protoreflect.Message) []fieldError {
+	if !m.IsValid() {
+		return nil
+	}
+	var errs []fieldError
+	desc := m.Descriptor()
+
+	if r, ok := rules[desc.FullName()]; ok {
+		for _, rule := range r.fields {
+			fd, v, ok := lookup(m, rule.path)
+			if !ok {
+				continue
+			}
+			for _, c := range rule.checks {
+				errs = append(errs, c(rule.path, fd, v)...)
+			}
+		}
+		if r.check != nil {
+			errs = append(errs, r.check(m.Interface())...)
+		}
+	}
+
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.IsMap() || !m.Has(fd) {
+			continue
+		}
+		name := string(fd.Name())
+		if fd.IsList() {
+			list := m.Get(fd).List()
+			for j := range list.Len() {
+				errs = append(errs, nested(fmt.Sprintf("%s[%d]", name, j), list.Get(j).Message())...)
+			}
+			continue
+		}
+		errs = append(errs, nested(name, m.Get(fd).Message())...)
+	}
+	return errs
+}
+
+// nested validates a sub-message and prefixes the paths of its violations.
+func nested(prefix string, m protoreflect.Message) []fieldError {
+	errs := validateMessage(m)
+	for i := range errs {
+		errs[i].path = prefix + "." + errs[i].path
+	}
+	return errs
+}
+
+// lookup resolves a dotted field path. ok is false when an intermediate
+// message along the path is unset. Paths are fixed in rules, so an unknown
+// field is a programming error and panics.
+func lookup(m protoreflect.Message, path string) (protoreflect.FieldDescriptor, protoreflect.Value, bool) {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			panic(fmt.Sprintf("validation: %s has no field %q", m.Descriptor().FullName(), name))
+		}
+		if i == len(names)-1 {
+			return fd, m.Get(fd), true
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			panic(fmt.Sprintf("validation: cannot descend into %s in %q", fd.FullName(), path))
+		}
+		if !m.Has(fd) {
+			return nil, protoreflect.Value{}, false
+		}
+		m = m.Get(fd).Message()
+	}
+	return nil, protoreflect.Value{}, false
+}
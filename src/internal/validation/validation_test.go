@@ -0,0 +1,179 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	bookiePb "github.com/sadhakbj/bookie-grpc/protos/bookie"
+)
+
+// TestRulePaths makes sure every rule names an existing field, since lookup
+// panics on typos only when a request happens to reach the rule.
+func TestRulePaths(t *testing.T) {
+	for msgName, r := range rules {
+		mt, err := protoregistry.GlobalTypes.FindMessageByName(msgName)
+		if err != nil {
+			t.Errorf("%s: %v", msgName, err)
+			continue
+		}
+		for _, rule := range r.fields {
+			md := mt.Descriptor()
+			names := strings.Split(rule.path, ".")
+			for i, n := range names {
+				fd := md.Fields().ByName(protoreflect.Name(n))
+				if fd == nil {
+					t.Errorf("%s: rule %q: no field %q", msgName, rule.path, n)
+					break
+				}
+				if i < len(names)-1 {
+					md = fd.Message()
+					if md == nil || fd.IsList() {
+						t.Errorf("%s: rule %q: %q is not a singular message", msgName, rule.path, n)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want map[string]string // field -> reason
+	}{
+		{
+			name: "valid create",
+			msg:  &bookiePb.CreateBookRequest{Id: "book-1", Title: "Dune", Price: 120},
+		},
+		{
+			name: "create without title and negative price",
+			msg:  &bookiePb.CreateBookRequest{Price: -1},
+			want: map[string]string{"title": ReasonRequired, "price": ReasonOutOfRange},
+		},
+		{
+			name: "create with long description and unsafe id",
+			msg: &bookiePb.CreateBookRequest{
+				Id:          "a/b",
+				Title:       "Dune",
+				Description: strings.Repeat("x", maxDescriptionLength+1),
+			},
+			want: map[string]string{"id": ReasonInvalidFormat, "description": ReasonTooLong},
+		},
+		{
+			name: "title length counts characters",
+			msg:  &bookiePb.CreateBookRequest{Title: strings.Repeat("é", maxTitleLength)},
+		},
+		{
+			name: "get without id",
+			msg:  &bookiePb.GetByIDRequest{},
+			want: map[string]string{"id": ReasonRequired},
+		},
+		{
+			name: "list with negative page size",
+			msg:  &bookiePb.ListBookRequest{PageSize: -5},
+			want: map[string]string{"page_size": ReasonOutOfRange},
+		},
+		{
+			name: "update without book",
+			msg:  &bookiePb.UpdateBookRequest{},
+			want: map[string]string{"book": ReasonRequired},
+		},
+		{
+			name: "update with unknown mask path and empty title",
+			msg: &bookiePb.UpdateBookRequest{
+				Book:       &bookiePb.Book{Id: "1"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title", "id"}},
+			},
+			want: map[string]string{"update_mask.paths[1]": ReasonNotAllowed, "book.title": ReasonRequired},
+		},
+		{
+			name: "update of price only may leave title empty",
+			msg: &bookiePb.UpdateBookRequest{
+				Book:       &bookiePb.Book{Id: "1", Price: -3},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"price"}},
+			},
+			want: map[string]string{"book.price": ReasonOutOfRange},
+		},
+		{
+			name: "watch from negative revision",
+			msg:  &bookiePb.WatchBooksRequest{StartAfterRevision: -1},
+			want: map[string]string{"start_after_revision": ReasonOutOfRange},
+		},
+		{
+			name: "empty batch create",
+			msg:  &bookiePb.BatchCreateBooksRequest{},
+			want: map[string]string{"requests": ReasonTooFewItems},
+		},
+		{
+			name: "batch create reports nested and duplicate items",
+			msg: &bookiePb.BatchCreateBooksRequest{Requests: []*bookiePb.CreateBookRequest{
+				{Id: "a", Title: "One"},
+				{Id: "b"},
+				{Id: "a", Title: "Three"},
+			}},
+			want: map[string]string{"requests[1].title": ReasonRequired, "requests[2].id": ReasonDuplicate},
+		},
+		{
+			name: "batch get with empty id",
+			msg:  &bookiePb.BatchGetBooksRequest{Ids: []string{"1", ""}},
+			want: map[string]string{"ids[1]": ReasonRequired},
+		},
+		{
+			name: "message without rules",
+			msg:  &bookiePb.ImportBooksRequest{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]string{}
+			for _, v := range Validate(tt.msg) {
+				got[v.GetField()] = v.GetReason()
+				if !strings.HasPrefix(v.GetDescription(), v.GetField()+" ") {
+					t.Errorf("description %q does not name field %q", v.GetDescription(), v.GetField())
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", got, tt.want)
+			}
+			for field, reason := range tt.want {
+				if got[field] != reason {
+					t.Errorf("Validate()[%s] = %q, want %q (all: %v)", field, got[field], reason, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(&bookiePb.GetByIDRequest{Id: "1"}); err != nil {
+		t.Fatalf("Check(valid) = %v", err)
+	}
+
+	err := Check(&bookiePb.CreateBookRequest{Price: -1})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %s, want InvalidArgument", st.Code())
+	}
+	if want := "Invalid CreateBookRequest: title is required (and 1 more)"; st.Message() != want {
+		t.Errorf("message = %q, want %q", st.Message(), want)
+	}
+	var badRequest *errdetails.BadRequest
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			badRequest = br
+		}
+	}
+	if len(badRequest.GetFieldViolations()) != 2 {
+		t.Errorf("details = %v, want two field violations", st.Details())
+	}
+}